@@ -0,0 +1,80 @@
+package tinygo_escmotor
+
+import (
+	"time"
+)
+
+type (
+	// clock is the source of time of the handler timing logic, the deadlines and sleeps of the ramps, the
+	// direction-change delays, the failsafe feeds, the ticks of the watchdog and the idle keep-alive and the deferred
+	// commands of the rate limiter, replaceable so the timing logic runs without waiting for the wall clock
+	clock interface {
+		Now() time.Time
+		Sleep(duration time.Duration)
+		After(duration time.Duration) <-chan time.Time
+		AfterFunc(duration time.Duration, f func()) func() bool
+		NewTicker(duration time.Duration) (<-chan time.Time, func())
+	}
+
+	// realClock is the clock backed by the time package
+	realClock struct{}
+)
+
+// Now returns the current time
+//
+// Returns:
+//
+// The current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses the current goroutine for the duration
+//
+// Parameters:
+//
+// duration: The duration to sleep
+func (realClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel
+//
+// Parameters:
+//
+// duration: The duration to wait
+//
+// Returns:
+//
+// The channel receiving the time once the duration elapses
+func (realClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+// AfterFunc waits for the duration to elapse and then calls the function in its own goroutine
+//
+// Parameters:
+//
+// duration: The duration to wait
+// f: The function to call
+//
+// Returns:
+//
+// The function stopping the timer, returning false if the function has already been called or the timer stopped
+func (realClock) AfterFunc(duration time.Duration, f func()) func() bool {
+	return time.AfterFunc(duration, f).Stop
+}
+
+// NewTicker starts a ticker sending the current time on the returned channel every duration
+//
+// Parameters:
+//
+// duration: The duration between two ticks, it must be positive
+//
+// Returns:
+//
+// The channel receiving the ticks and the function stopping the ticker
+func (realClock) NewTicker(duration time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(duration)
+	return ticker.C, ticker.Stop
+}
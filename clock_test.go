@@ -0,0 +1,243 @@
+package tinygo_escmotor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// fakeClock is the clock whose time only moves when the handler sleeps or waits, whose timers fire once the time
+	// moves past them and whose tickers only tick when the test calls Tick, so the timing logic runs instantly and
+	// deterministically
+	fakeClock struct {
+		mutex   sync.Mutex
+		now     time.Time
+		slept   time.Duration
+		timers  []*fakeTimer
+		tickers []*fakeTicker
+	}
+
+	// fakeTimer is a timer created by a fakeClock
+	fakeTimer struct {
+		deadline  time.Time
+		f         func()
+		isStopped bool
+	}
+
+	// fakeTicker is a ticker created by a fakeClock
+	fakeTicker struct {
+		duration  time.Duration
+		ticks     chan time.Time
+		isStopped bool
+	}
+)
+
+// newFakeClock creates a new instance of fakeClock
+//
+// Returns:
+//
+// An instance of fakeClock starting at an arbitrary fixed time
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// Now returns the current fake time
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Sleep moves the fake time forward by the duration without waiting
+func (c *fakeClock) Sleep(duration time.Duration) {
+	c.Advance(duration)
+}
+
+// After moves the fake time forward by the duration and returns a channel that already received it
+func (c *fakeClock) After(duration time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(duration)
+	return ch
+}
+
+// AfterFunc creates a timer calling the function once the fake time moves past the duration
+func (c *fakeClock) AfterFunc(duration time.Duration, f func()) func() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &fakeTimer{
+		deadline: c.now.Add(duration),
+		f:        f,
+	}
+	c.timers = append(c.timers, timer)
+	return func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		isPending := !timer.isStopped
+		timer.isStopped = true
+		return isPending
+	}
+}
+
+// NewTicker creates a ticker that only ticks when Tick is called
+func (c *fakeClock) NewTicker(duration time.Duration) (<-chan time.Time, func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ticker := &fakeTicker{
+		duration: duration,
+		ticks:    make(chan time.Time),
+	}
+	c.tickers = append(c.tickers, ticker)
+	return ticker.ticks, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		ticker.isStopped = true
+	}
+}
+
+// Advance moves the fake time forward, calling the functions of the timers it moves past before returning
+//
+// Parameters:
+//
+// duration: The duration to move forward, the negative durations are ignored
+//
+// Returns:
+//
+// The new fake time
+func (c *fakeClock) Advance(duration time.Duration) time.Time {
+	c.mutex.Lock()
+	if duration > 0 {
+		c.now = c.now.Add(duration)
+		c.slept += duration
+	}
+	now := c.now
+
+	// Pick the timers that are due
+	var due []func()
+	for _, timer := range c.timers {
+		if !timer.isStopped && !timer.deadline.After(now) {
+			timer.isStopped = true
+			due = append(due, timer.f)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, f := range due {
+		f()
+	}
+	return now
+}
+
+// Slept returns the total duration the fake time moved forward
+func (c *fakeClock) Slept() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.slept
+}
+
+// Tick delivers one tick to every running ticker of the duration, waiting for them to be created first. The ticks are
+// unbuffered, so once Tick returns for the second time the first tick has been handled.
+//
+// Parameters:
+//
+// t: The test
+// duration: The duration of the tickers to tick
+func (c *fakeClock) Tick(t *testing.T, duration time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.mutex.Lock()
+		var tickers []*fakeTicker
+		for _, ticker := range c.tickers {
+			if ticker.duration == duration && !ticker.isStopped {
+				tickers = append(tickers, ticker)
+			}
+		}
+		now := c.now
+		c.mutex.Unlock()
+
+		if len(tickers) > 0 {
+			for _, ticker := range tickers {
+				select {
+				case ticker.ticks <- now:
+				case <-time.After(time.Second):
+					t.Fatalf("ticker of %v did not receive the tick", duration)
+				}
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no ticker of %v was created", duration)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TickAndWait delivers a tick and waits for it to be handled
+//
+// Parameters:
+//
+// t: The test
+// duration: The duration of the tickers to tick
+func (c *fakeClock) TickAndWait(t *testing.T, duration time.Duration) {
+	t.Helper()
+	c.Tick(t, duration)
+	c.Tick(t, duration)
+}
+
+func TestWatchdogTicksOnTheHandlerClock(t *testing.T) {
+	clock := newFakeClock()
+	h := newTestHandler(t, withClock(clock), WithFailsafe(100*time.Millisecond))
+
+	if errCode := h.SetSpeedForward(0.5); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("SetSpeedForward() = %v", errCode)
+	}
+
+	// A tick within the timeout keeps the motor running
+	clock.TickAndWait(t, 25*time.Millisecond)
+	if h.IsFailsafeActive() || !h.IsMoving() {
+		t.Fatal("failsafe triggered before the timeout elapsed")
+	}
+
+	// A tick past the timeout stops the motor
+	clock.Advance(200 * time.Millisecond)
+	clock.TickAndWait(t, 25*time.Millisecond)
+	if !h.IsFailsafeActive() {
+		t.Fatal("failsafe not triggered after the timeout elapsed")
+	}
+	if !h.IsStopped() {
+		t.Fatalf("IsStopped() = false, pulse %d", h.GetPulse())
+	}
+}
+
+func TestIdleKeepAliveTicksOnTheHandlerClock(t *testing.T) {
+	clock := newFakeClock()
+	recorder := &pulseRecorder{}
+	h := newTestHandler(t, withClock(clock), WithIdleKeepAlive(time.Second), recorder.option())
+
+	// Only the first tick is known to be handled once TickAndWait returns
+	before := recorder.count()
+	clock.TickAndWait(t, time.Second)
+	writes := recorder.since(before)
+	if len(writes) < 1 {
+		t.Fatal("keep-alive wrote no pulse width on a tick")
+	}
+	if last := writes[len(writes)-1]; last != h.GetNeutralPulseWidth() {
+		t.Fatalf("keep-alive wrote %d, want the neutral pulse width %d", last, h.GetNeutralPulseWidth())
+	}
+
+	// A coasting motor is left alone
+	if errCode := h.Coast(); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("Coast() = %v", errCode)
+	}
+	before = recorder.count()
+	clock.TickAndWait(t, time.Second)
+	if writes = recorder.since(before); len(writes) != 0 {
+		t.Fatalf("keep-alive wrote %v while coasting", writes)
+	}
+}
@@ -0,0 +1,219 @@
+package tinygo_escmotor
+
+import (
+	"sync"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// ClosedLoopHandler is the implementation to hold a target RPM with a PID controller driving the throttle of a
+	// wrapped handler from the RPM feedback. The target and the gains can be changed while the control loop runs.
+	ClosedLoopHandler struct {
+		handler          Handler
+		feedback         func() float64
+		clock            clock
+		mutex            sync.Mutex
+		maxForwardSpeed  float64
+		maxBackwardSpeed float64
+		targetRPM        float64
+		kp               float64
+		ki               float64
+		kd               float64
+		integral         float64
+		lastError        float64
+		lastUpdate       time.Time
+		throttle         float64
+	}
+
+	// clockSource is the interface implemented by the handlers whose source of time can be shared
+	clockSource interface {
+		getClock() clock
+	}
+)
+
+// NewClosedLoopHandler creates a new instance of ClosedLoopHandler
+//
+// Parameters:
+//
+// handler: The handler of the motor
+// feedback: Function returning the measured RPM, negative when moving backward
+// maxForwardSpeed: The maximum forward speed the controller can command, also bounding the integral term
+// maxBackwardSpeed: The maximum backward speed the controller can command, also bounding the integral term
+//
+// Returns:
+//
+// An instance of ClosedLoopHandler and an error if any occurred during initialization
+func NewClosedLoopHandler(
+	handler Handler,
+	feedback func() float64,
+	maxForwardSpeed float64,
+	maxBackwardSpeed float64,
+) (*ClosedLoopHandler, tinygoerrors.ErrorCode) {
+	// Check if the handler or the feedback are nil
+	if handler == nil || feedback == nil {
+		return nil, ErrorCodeESCMotorNilHandler
+	}
+
+	// Check if the max forward speed is valid
+	if maxForwardSpeed <= 0 || maxForwardSpeed > 1 {
+		return nil, ErrorCodeESCMotorInvalidMaxForwardSpeed
+	}
+
+	// Check if the max backward speed is valid
+	if maxBackwardSpeed < 0 || maxBackwardSpeed > 1 {
+		return nil, ErrorCodeESCMotorInvalidMaxBackwardSpeed
+	}
+
+	// Share the source of time of the handler, falling back to the wall clock
+	var source clock = realClock{}
+	if provider, ok := handler.(clockSource); ok {
+		source = provider.getClock()
+	}
+
+	return &ClosedLoopHandler{
+		handler:          handler,
+		feedback:         feedback,
+		clock:            source,
+		maxForwardSpeed:  maxForwardSpeed,
+		maxBackwardSpeed: maxBackwardSpeed,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// SetTargetRPM sets the RPM the controller holds.
+//
+// Parameters:
+//
+// target: The target RPM, negative for backward
+func (c *ClosedLoopHandler) SetTargetRPM(target float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.targetRPM = target
+}
+
+// GetTargetRPM returns the RPM the controller holds.
+//
+// Returns:
+//
+// The target RPM
+func (c *ClosedLoopHandler) GetTargetRPM() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.targetRPM
+}
+
+// SetPIDGains sets the gains of the PID controller, mapping the RPM error onto the throttle.
+//
+// Parameters:
+//
+// kp: The proportional gain
+// ki: The integral gain, per second
+// kd: The derivative gain, in seconds
+func (c *ClosedLoopHandler) SetPIDGains(kp, ki, kd float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.kp = kp
+	c.ki = ki
+	c.kd = kd
+}
+
+// GetThrottle returns the last throttle commanded by the controller.
+//
+// Returns:
+//
+// The throttle between -maxBackwardSpeed and maxForwardSpeed
+func (c *ClosedLoopHandler) GetThrottle() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.throttle
+}
+
+// Reset clears the integral and derivative state of the controller, e.g. after the motor has been stopped externally.
+func (c *ClosedLoopHandler) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reset()
+}
+
+// reset clears the integral and derivative state of the controller, the mutex must be held
+func (c *ClosedLoopHandler) reset() {
+	c.integral = 0
+	c.lastError = 0
+	c.lastUpdate = time.Time{}
+}
+
+// Update runs one step of the PID controller, computing the throttle from the RPM error and setting it on the handler.
+// It is meant to be called at a fixed rate from the control loop. The time elapsed between two steps is measured on the
+// clock of the wrapped handler when it is a DefaultHandler, otherwise on the wall clock.
+//
+// Returns:
+//
+// The RPM error of the step and an error if the throttle could not be set, otherwise nil
+func (c *ClosedLoopHandler) Update() (float64, tinygoerrors.ErrorCode) {
+	rpmError, throttle := c.step(c.feedback())
+
+	// Set the throttle outside the lock, the handler may block while ramping
+	return rpmError, setSignedSpeed(c.handler, throttle)
+}
+
+// step runs one step of the PID controller from an RPM reading
+//
+// Parameters:
+//
+// rpm: The measured RPM
+//
+// Returns:
+//
+// The RPM error and the throttle of the step
+func (c *ClosedLoopHandler) step(rpm float64) (float64, float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	rpmError := c.targetRPM - rpm
+
+	// Integrate and derive the error over the time elapsed since the previous step
+	now := c.clock.Now()
+	var derivative float64
+	if !c.lastUpdate.IsZero() {
+		dt := now.Sub(c.lastUpdate).Seconds()
+		if dt > 0 {
+			c.integral += rpmError * dt
+			derivative = (rpmError - c.lastError) / dt
+		}
+	}
+	c.lastError = rpmError
+	c.lastUpdate = now
+
+	// Clamp the integral term to the max speeds to prevent windup
+	if c.ki != 0 {
+		if c.ki*c.integral > c.maxForwardSpeed {
+			c.integral = c.maxForwardSpeed / c.ki
+		} else if c.ki*c.integral < -c.maxBackwardSpeed {
+			c.integral = -c.maxBackwardSpeed / c.ki
+		}
+	}
+
+	// Compute the throttle and clamp it to the max speeds
+	throttle := c.kp*rpmError + c.ki*c.integral + c.kd*derivative
+	if throttle > c.maxForwardSpeed {
+		throttle = c.maxForwardSpeed
+	} else if throttle < -c.maxBackwardSpeed {
+		throttle = -c.maxBackwardSpeed
+	}
+	c.throttle = throttle
+	return rpmError, throttle
+}
+
+// Stop stops the motor and resets the controller.
+//
+// Returns:
+//
+// An error if the motor could not be stopped, otherwise nil
+func (c *ClosedLoopHandler) Stop() tinygoerrors.ErrorCode {
+	c.mutex.Lock()
+	c.targetRPM = 0
+	c.throttle = 0
+	c.reset()
+	c.mutex.Unlock()
+	return c.handler.Stop()
+}
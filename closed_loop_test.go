@@ -0,0 +1,191 @@
+package tinygo_escmotor
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// mockHandler is the Handler recording the last speed set, on a fake clock
+	mockHandler struct {
+		mutex sync.Mutex
+		clock *fakeClock
+		speed float64
+		calls int
+	}
+)
+
+// getClock returns the fake clock of the mock handler
+func (m *mockHandler) getClock() clock {
+	return m.clock
+}
+
+// set records a signed speed
+func (m *mockHandler) set(speed float64) tinygoerrors.ErrorCode {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.speed = speed
+	m.calls++
+	return tinygoerrors.ErrorCodeNil
+}
+
+func (m *mockHandler) GetSpeed() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.speed
+}
+
+func (m *mockHandler) Stop() tinygoerrors.ErrorCode {
+	return m.set(0)
+}
+
+func (m *mockHandler) SetSpeed(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	switch direction {
+	case DirectionForward:
+		return m.set(speed)
+	case DirectionBackward:
+		return m.set(-speed)
+	default:
+		return m.set(0)
+	}
+}
+
+func (m *mockHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	return m.set(speed)
+}
+
+func (m *mockHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
+	return m.set(-speed)
+}
+
+func (m *mockHandler) IsStopped() bool {
+	return m.GetSpeed() == 0
+}
+
+func (m *mockHandler) IsMoving() bool {
+	return !m.IsStopped()
+}
+
+func TestClosedLoopUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		kp, ki, kd float64
+		target     float64
+		readings   []float64
+		want       []float64
+	}{
+		{
+			name:     "proportional",
+			kp:       0.001,
+			target:   1000,
+			readings: []float64{500, 900},
+			want:     []float64{0.5, 0.1},
+		},
+		{
+			name:     "proportional backward",
+			kp:       0.0005,
+			target:   -1000,
+			readings: []float64{0},
+			want:     []float64{-0.5},
+		},
+		{
+			name:     "clamped to the max forward speed",
+			kp:       1,
+			target:   1000,
+			readings: []float64{0},
+			want:     []float64{0.8},
+		},
+		{
+			name:     "clamped to the max backward speed",
+			kp:       1,
+			target:   -1000,
+			readings: []float64{0},
+			want:     []float64{-0.6},
+		},
+		{
+			// The first step has no elapsed time, the next ones integrate 1000 RPM over a second each
+			name:     "integral",
+			ki:       0.0001,
+			target:   1000,
+			readings: []float64{0, 0, 0},
+			want:     []float64{0, 0.1, 0.2},
+		},
+		{
+			// The integral is held at the max forward speed while saturated, so it unwinds at once on overshoot
+			name:     "anti-windup",
+			ki:       1,
+			target:   1000,
+			readings: []float64{0, 0, 0, 0, 1000.1},
+			want:     []float64{0, 0.8, 0.8, 0.8, 0.7},
+		},
+		{
+			name:     "derivative",
+			kd:       0.001,
+			target:   1000,
+			readings: []float64{1000, 900, 900},
+			want:     []float64{0, 0.1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var reading float64
+				handler := &mockHandler{clock: newFakeClock()}
+				c, errCode := NewClosedLoopHandler(handler, func() float64 { return reading }, 0.8, 0.6)
+				if errCode != tinygoerrors.ErrorCodeNil {
+					t.Fatalf("NewClosedLoopHandler() = %v", errCode)
+				}
+				c.SetPIDGains(tt.kp, tt.ki, tt.kd)
+				c.SetTargetRPM(tt.target)
+
+				for i, want := range tt.want {
+					reading = tt.readings[i]
+					rpmError, errCode := c.Update()
+					if errCode != tinygoerrors.ErrorCodeNil {
+						t.Fatalf("step %d: Update() = %v", i, errCode)
+					}
+					if rpmError != tt.target-reading {
+						t.Fatalf("step %d: RPM error %v, want %v", i, rpmError, tt.target-reading)
+					}
+					if got := handler.GetSpeed(); math.Abs(got-want) > 1e-9 {
+						t.Fatalf("step %d: speed %v, want %v", i, got, want)
+					}
+					handler.clock.Advance(time.Second)
+				}
+			},
+		)
+	}
+}
+
+func TestClosedLoopConcurrentTuning(t *testing.T) {
+	handler := &mockHandler{clock: newFakeClock()}
+	c, errCode := NewClosedLoopHandler(handler, func() float64 { return 500 }, 1, 1)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewClosedLoopHandler() = %v", errCode)
+	}
+
+	// Tune the controller while the control loop runs, the race detector flags any unguarded field
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, _ = c.Update()
+			handler.clock.Advance(time.Millisecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.SetTargetRPM(float64(i * 10))
+			c.SetPIDGains(0.001, 0.0001, 0)
+			_ = c.GetThrottle()
+		}
+	}()
+	wg.Wait()
+}
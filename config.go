@@ -0,0 +1,173 @@
+package tinygo_escmotor
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// Config is the tuning profile of an ESC (Electronic Speed Controller) motor. When IsUnidirectional is set, the
+	// NeutralPulseWidth is ignored and the motor stops at the MinPulseWidth.
+	Config struct {
+		Frequency              uint16
+		MinPulseWidth          uint32
+		NeutralPulseWidth      uint32
+		MaxPulseWidth          uint32
+		IsPolarityInverted     bool
+		IsSignalInverted       bool
+		IsUnidirectional       bool
+		MaxForwardSpeed        float64
+		MaxBackwardSpeed       float64
+		PulseStep              *uint32
+		BackwardToForwardDelay time.Duration
+		ForwardToBackwardDelay time.Duration
+		PWMConfigureRetries    uint8
+		PWMConfigureRetryDelay time.Duration
+	}
+)
+
+const (
+	// ConfigVersion is the version of the binary layout used to serialize a Config
+	ConfigVersion uint8 = 1
+
+	// ConfigSize is the size in bytes of a serialized Config
+	ConfigSize = 52
+)
+
+const (
+	// configFlagPolarityInverted is the flag set when the motor polarity is inverted
+	configFlagPolarityInverted uint8 = 1 << iota
+
+	// configFlagSignalInverted is the flag set when the PWM signal is inverted
+	configFlagSignalInverted
+
+	// configFlagPulseStep is the flag set when the pulse step is present
+	configFlagPulseStep
+
+	// configFlagUnidirectional is the flag set when the motor is unidirectional
+	configFlagUnidirectional
+)
+
+// GetConfig returns the current tuning profile of the ESC motor, with the neutral trim applied to the neutral pulse
+// width.
+//
+// Returns:
+//
+// The current tuning profile
+func (h *DefaultHandler) GetConfig() Config {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+
+	// Copy the pulse step, so the caller cannot change the ramp of the handler through it
+	var pulseStep *uint32
+	if h.pulseStep != nil {
+		step := *h.pulseStep
+		pulseStep = &step
+	}
+	return Config{
+		Frequency:              h.frequency,
+		MinPulseWidth:          h.minPulseWidth,
+		NeutralPulseWidth:      h.neutralPulseWidth,
+		MaxPulseWidth:          h.maxPulseWidth,
+		IsPolarityInverted:     h.isPolarityInverted,
+		IsSignalInverted:       h.isSignalInverted,
+		IsUnidirectional:       h.isUnidirectional,
+		MaxForwardSpeed:        h.maxForwardSpeed,
+		MaxBackwardSpeed:       h.maxBackwardSpeed,
+		PulseStep:              pulseStep,
+		BackwardToForwardDelay: h.backwardToForwardDelay,
+		ForwardToBackwardDelay: h.forwardToBackwardDelay,
+	}
+}
+
+// MarshalConfig serializes the current tuning profile of the ESC motor.
+//
+// Returns:
+//
+// The tuning profile serialized with the fixed binary layout of ConfigSize bytes
+func (h *DefaultHandler) MarshalConfig() []byte {
+	return MarshalConfig(h.GetConfig())
+}
+
+// MarshalConfig serializes a tuning profile using a fixed little-endian binary layout. The PWM configuration retry
+// settings describe the boot behavior rather than the tuning, so they are not serialized.
+//
+// Parameters:
+//
+// cfg: The tuning profile to serialize
+//
+// Returns:
+//
+// The tuning profile serialized with the fixed binary layout of ConfigSize bytes
+func MarshalConfig(cfg Config) []byte {
+	data := make([]byte, ConfigSize)
+	data[0] = ConfigVersion
+	binary.LittleEndian.PutUint16(data[1:3], cfg.Frequency)
+	binary.LittleEndian.PutUint32(data[3:7], cfg.MinPulseWidth)
+	binary.LittleEndian.PutUint32(data[7:11], cfg.NeutralPulseWidth)
+	binary.LittleEndian.PutUint32(data[11:15], cfg.MaxPulseWidth)
+	binary.LittleEndian.PutUint64(data[15:23], math.Float64bits(cfg.MaxForwardSpeed))
+	binary.LittleEndian.PutUint64(data[23:31], math.Float64bits(cfg.MaxBackwardSpeed))
+	binary.LittleEndian.PutUint64(data[35:43], uint64(cfg.BackwardToForwardDelay))
+	binary.LittleEndian.PutUint64(data[43:51], uint64(cfg.ForwardToBackwardDelay))
+
+	// Set the flags and the optional pulse step
+	var flags uint8
+	if cfg.IsPolarityInverted {
+		flags |= configFlagPolarityInverted
+	}
+	if cfg.IsSignalInverted {
+		flags |= configFlagSignalInverted
+	}
+	if cfg.IsUnidirectional {
+		flags |= configFlagUnidirectional
+	}
+	if cfg.PulseStep != nil {
+		flags |= configFlagPulseStep
+		binary.LittleEndian.PutUint32(data[31:35], *cfg.PulseStep)
+	}
+	data[51] = flags
+	return data
+}
+
+// UnmarshalConfig deserializes a tuning profile serialized by MarshalConfig.
+//
+// Parameters:
+//
+// data: The serialized tuning profile
+//
+// Returns:
+//
+// The tuning profile and an error if the data has an invalid size or version, the values themselves are validated
+// when the handler is created
+func UnmarshalConfig(data []byte) (Config, tinygoerrors.ErrorCode) {
+	// Check the size and the version of the layout
+	if len(data) != ConfigSize || data[0] != ConfigVersion {
+		return Config{}, ErrorCodeESCMotorInvalidConfigData
+	}
+
+	flags := data[51]
+	cfg := Config{
+		Frequency:              binary.LittleEndian.Uint16(data[1:3]),
+		MinPulseWidth:          binary.LittleEndian.Uint32(data[3:7]),
+		NeutralPulseWidth:      binary.LittleEndian.Uint32(data[7:11]),
+		MaxPulseWidth:          binary.LittleEndian.Uint32(data[11:15]),
+		IsPolarityInverted:     flags&configFlagPolarityInverted != 0,
+		IsSignalInverted:       flags&configFlagSignalInverted != 0,
+		IsUnidirectional:       flags&configFlagUnidirectional != 0,
+		MaxForwardSpeed:        math.Float64frombits(binary.LittleEndian.Uint64(data[15:23])),
+		MaxBackwardSpeed:       math.Float64frombits(binary.LittleEndian.Uint64(data[23:31])),
+		BackwardToForwardDelay: time.Duration(binary.LittleEndian.Uint64(data[35:43])),
+		ForwardToBackwardDelay: time.Duration(binary.LittleEndian.Uint64(data[43:51])),
+	}
+
+	// Get the optional pulse step
+	if flags&configFlagPulseStep != 0 {
+		pulseStep := binary.LittleEndian.Uint32(data[31:35])
+		cfg.PulseStep = &pulseStep
+	}
+	return cfg, tinygoerrors.ErrorCodeNil
+}
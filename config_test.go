@@ -0,0 +1,54 @@
+package tinygo_escmotor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	h := newTestHandler(
+		t,
+		WithPulseStep(250),
+		WithMaxSpeeds(0.8, 0.6),
+		WithDirectionDelays(50*time.Millisecond, 75*time.Millisecond),
+	)
+	cfg := h.GetConfig()
+
+	got, errCode := UnmarshalConfig(h.MarshalConfig())
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("UnmarshalConfig() = %v", errCode)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("UnmarshalConfig() = %+v, want %+v", got, cfg)
+	}
+
+	// A profile without a pulse step stays without one
+	cfg.PulseStep = nil
+	if got, _ = UnmarshalConfig(MarshalConfig(cfg)); got.PulseStep != nil {
+		t.Fatalf("UnmarshalConfig() pulse step = %d, want nil", *got.PulseStep)
+	}
+}
+
+func TestUnmarshalConfigRejectsInvalidData(t *testing.T) {
+	data := MarshalConfig(Config{})
+	if _, errCode := UnmarshalConfig(data[:ConfigSize-1]); errCode != ErrorCodeESCMotorInvalidConfigData {
+		t.Fatalf("UnmarshalConfig(short) = %v, want ErrorCodeESCMotorInvalidConfigData", errCode)
+	}
+	data[0] = ConfigVersion + 1
+	if _, errCode := UnmarshalConfig(data); errCode != ErrorCodeESCMotorInvalidConfigData {
+		t.Fatalf("UnmarshalConfig(version) = %v, want ErrorCodeESCMotorInvalidConfigData", errCode)
+	}
+}
+
+func TestGetConfigCopiesThePulseStep(t *testing.T) {
+	h := newTestHandler(t, WithPulseStep(250))
+	cfg := h.GetConfig()
+	*cfg.PulseStep = 1
+
+	if step, ok := h.GetPulseStep(); !ok || step != 250 {
+		t.Fatalf("GetPulseStep() = %d, %v, want 250, true", step, ok)
+	}
+}
@@ -0,0 +1,83 @@
+package tinygo_escmotor
+
+import (
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// DifferentialDrive is the implementation to drive two ESC motors as the left and right wheels of a differential
+	// (tank, skid-steer) drive.
+	DifferentialDrive struct {
+		left  Handler
+		right Handler
+	}
+)
+
+// NewDifferentialDrive creates a new instance of DifferentialDrive
+//
+// Parameters:
+//
+// left: The handler of the left wheel motor
+// right: The handler of the right wheel motor
+//
+// Returns:
+//
+// An instance of DifferentialDrive and an error if any of the handlers is nil
+func NewDifferentialDrive(left, right Handler) (*DifferentialDrive, tinygoerrors.ErrorCode) {
+	// Check if the handlers are nil
+	if left == nil || right == nil {
+		return nil, ErrorCodeESCMotorNilHandler
+	}
+
+	return &DifferentialDrive{
+		left:  left,
+		right: right,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// Drive mixes the throttle and the steering into the left and right wheel speeds, a steering with no throttle spins the
+// drive in place. Each wheel speed is clamped to its own max forward and backward speeds.
+//
+// Parameters:
+//
+// throttle: Throttle value between -1 (full backward) and 1 (full forward)
+// steering: Steering value between -1 (full left) and 1 (full right)
+//
+// Returns:
+//
+// An error if any of the wheel speeds could not be set, otherwise nil
+func (d *DifferentialDrive) Drive(throttle, steering float64) tinygoerrors.ErrorCode {
+	throttle = clampSignedSpeed(throttle)
+	steering = clampSignedSpeed(steering)
+
+	// Mix the inputs, steering right speeds up the left wheel and slows down the right one
+	leftErrCode := setSignedSpeed(d.left, clampSignedSpeed(throttle+steering))
+	rightErrCode := setSignedSpeed(d.right, clampSignedSpeed(throttle-steering))
+	if leftErrCode != tinygoerrors.ErrorCodeNil {
+		return leftErrCode
+	}
+	return rightErrCode
+}
+
+// StopAll stops both wheel motors.
+//
+// Returns:
+//
+// An error if any of the wheel motors could not be stopped, otherwise nil
+func (d *DifferentialDrive) StopAll() tinygoerrors.ErrorCode {
+	leftErrCode := d.left.Stop()
+	rightErrCode := d.right.Stop()
+	if leftErrCode != tinygoerrors.ErrorCodeNil {
+		return leftErrCode
+	}
+	return rightErrCode
+}
+
+// GetWheelSpeeds returns the current speeds of the wheel motors.
+//
+// Returns:
+//
+// The left and right wheel speeds, negative when moving backward
+func (d *DifferentialDrive) GetWheelSpeeds() (left, right float64) {
+	return d.left.GetSpeed(), d.right.GetSpeed()
+}
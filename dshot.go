@@ -0,0 +1,491 @@
+package tinygo_escmotor
+
+import (
+	"machine"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+	tinygologger "github.com/ralvarezdev/tinygo-logger"
+)
+
+type (
+	// DShotTransmitter is the interface to send DShot frames on the ESC signal line, e.g. by bit-banging the pin like
+	// PinDShotTransmitter does, a PIO state machine or a DMA-driven timer, at the bit rate of the chosen DShot protocol.
+	DShotTransmitter interface {
+		Transmit(frame uint16) error
+	}
+
+	// PinDShotTransmitter is the DShotTransmitter bit-banging the frames on a GPIO pin, busy-waiting for the bit timings
+	// of the DShot protocol. The interrupts should be disabled around Transmit, since a late edge corrupts the frame,
+	// and only the fast targets keep up with DShot300 and above, where a PIO state machine or a DMA-driven timer is the
+	// better fit.
+	PinDShotTransmitter struct {
+		set          func(isHigh bool)
+		clock        clock
+		bitPeriod    time.Duration
+		oneHighTime  time.Duration
+		zeroHighTime time.Duration
+	}
+
+	// DShotHandler is the implementation to handle ESC (Electronic Speed Controller) motor operations with the DShot
+	// digital protocol in 3D (bidirectional) mode.
+	DShotHandler struct {
+		transmitter          DShotTransmitter
+		protocol             DShotProtocol
+		afterSetSpeedFunc    func(speed float64)
+		isMovementEnabled    func() bool
+		isPolarityInverted   bool
+		isTelemetryRequested bool
+		maxForwardSpeed      float64
+		maxBackwardSpeed     float64
+		speed                float64
+		direction            Direction
+		throttle             uint16
+		logger               tinygologger.Logger
+	}
+)
+
+const (
+	// DShotThrottleDisarmed is the DShot throttle value reserved to stop and disarm the motor
+	DShotThrottleDisarmed uint16 = 0
+
+	// DShotThrottleMin is the lowest DShot throttle value, the values below it are reserved for special commands
+	DShotThrottleMin uint16 = 48
+
+	// DShotThrottleMax is the highest DShot throttle value
+	DShotThrottleMax uint16 = 2047
+
+	// DShot3DForwardThrottleMin is the lowest forward throttle value in 3D mode, the values from DShotThrottleMin up to
+	// it are the backward throttle values
+	DShot3DForwardThrottleMin uint16 = 1048
+
+	// dshot3DThrottleSpan is the span of the throttle values for each direction in 3D mode
+	dshot3DThrottleSpan = DShotThrottleMax - DShot3DForwardThrottleMin
+
+	// dshotFrameBits is the number of bits of a DShot frame
+	dshotFrameBits = 16
+)
+
+// NewPinDShotTransmitter creates a new instance of PinDShotTransmitter, configuring the pin as an output driven low.
+//
+// Parameters:
+//
+// pin: The pin connected to the ESC signal line
+// protocol: The DShot protocol setting the bit rate
+//
+// Returns:
+//
+// An instance of PinDShotTransmitter and an error if the protocol is unknown
+func NewPinDShotTransmitter(pin machine.Pin, protocol DShotProtocol) (*PinDShotTransmitter, tinygoerrors.ErrorCode) {
+	transmitter, errCode := newPinDShotTransmitter(pin.Set, realClock{}, protocol)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return nil, errCode
+	}
+
+	// Configure the pin as an output, idle low between the frames
+	pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	pin.Low()
+	return transmitter, tinygoerrors.ErrorCodeNil
+}
+
+// newPinDShotTransmitter creates a new instance of PinDShotTransmitter driving the line through a function
+//
+// Parameters:
+//
+// set: The function driving the signal line high or low
+// source: The clock timing the bits
+// protocol: The DShot protocol setting the bit rate
+//
+// Returns:
+//
+// An instance of PinDShotTransmitter and an error if the protocol is unknown
+func newPinDShotTransmitter(
+	set func(isHigh bool),
+	source clock,
+	protocol DShotProtocol,
+) (*PinDShotTransmitter, tinygoerrors.ErrorCode) {
+	// Check if the protocol is known
+	bitRate := protocol.BitRate()
+	if bitRate == 0 {
+		return nil, ErrorCodeESCMotorInvalidDShotProtocol
+	}
+
+	// A one is high for three quarters of the bit period and a zero for three eighths of it
+	bitPeriod := time.Second / time.Duration(bitRate)
+	return &PinDShotTransmitter{
+		set:          set,
+		clock:        source,
+		bitPeriod:    bitPeriod,
+		oneHighTime:  bitPeriod * 3 / 4,
+		zeroHighTime: bitPeriod * 3 / 8,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// Transmit bit-bangs a DShot frame, most significant bit first, timing every edge from the start of the frame so the
+// delays do not accumulate.
+//
+// Parameters:
+//
+// frame: The 16-bit DShot frame
+//
+// Returns:
+//
+// An error if a bit overran its period, in which case the ESC discards the frame, otherwise nil
+func (t *PinDShotTransmitter) Transmit(frame uint16) error {
+	var err error
+	start := t.clock.Now()
+	for i := 0; i < dshotFrameBits; i++ {
+		// Get the high time of the bit
+		highTime := t.zeroHighTime
+		if frame&(1<<(dshotFrameBits-1-i)) != 0 {
+			highTime = t.oneHighTime
+		}
+
+		// Drive the bit
+		bitStart := start.Add(time.Duration(i) * t.bitPeriod)
+		t.waitUntil(bitStart)
+		t.set(true)
+		t.waitUntil(bitStart.Add(highTime))
+		t.set(false)
+
+		// Check if the bit overran its period
+		if t.clock.Now().After(bitStart.Add(t.bitPeriod)) {
+			err = errDShotBitTimingMissed
+		}
+	}
+
+	// Hold the line low until the end of the last bit
+	t.waitUntil(start.Add(dshotFrameBits * t.bitPeriod))
+	return err
+}
+
+// waitUntil busy-waits until the deadline, sleeping would overshoot the bit timings by far
+//
+// Parameters:
+//
+// deadline: The time to wait for
+func (t *PinDShotTransmitter) waitUntil(deadline time.Time) {
+	for t.clock.Now().Before(deadline) {
+	}
+}
+
+// EncodeDShotFrame encodes a DShot frame with the 11-bit throttle value, the telemetry request bit and the 4-bit CRC.
+//
+// Parameters:
+//
+// throttle: The 11-bit throttle value
+// isTelemetryRequested: Whether the ESC should send telemetry back
+//
+// Returns:
+//
+// The 16-bit DShot frame
+func EncodeDShotFrame(throttle uint16, isTelemetryRequested bool) uint16 {
+	value := (throttle & DShotThrottleMax) << 1
+	if isTelemetryRequested {
+		value |= 1
+	}
+	crc := (value ^ (value >> 4) ^ (value >> 8)) & 0x0F
+	return (value << 4) | crc
+}
+
+// NewDShotHandler creates a new instance of DShotHandler
+//
+// Parameters:
+//
+// transmitter: The transmitter to send the DShot frames
+// protocol: The DShot protocol bit rate used by the transmitter
+// afterSetSpeedFunc: Function to call after setting the speed
+// isMovementEnabled: Function to check if movement is enabled
+// isPolarityInverted: Whether the motor polarity is inverted
+// maxForwardSpeed: The maximum forward percentage speed value for the motor
+// maxBackwardSpeed: The maximum backward percentage speed value for the motor
+// logger: The logger to log messages
+//
+// Returns:
+//
+// An instance of DShotHandler and an error if any occurred during initialization
+func NewDShotHandler(
+	transmitter DShotTransmitter,
+	protocol DShotProtocol,
+	afterSetSpeedFunc func(speed float64),
+	isMovementEnabled func() bool,
+	isPolarityInverted bool,
+	maxForwardSpeed float64,
+	maxBackwardSpeed float64,
+	logger tinygologger.Logger,
+) (*DShotHandler, tinygoerrors.ErrorCode) {
+	// Check if the transmitter is nil
+	if transmitter == nil {
+		return nil, ErrorCodeESCMotorNilDShotTransmitter
+	}
+
+	// Check if the protocol is known
+	if protocol.BitRate() == 0 {
+		return nil, ErrorCodeESCMotorInvalidDShotProtocol
+	}
+
+	// Check if the max forward speed is valid
+	if maxForwardSpeed <= 0 || maxForwardSpeed > 1 {
+		return nil, ErrorCodeESCMotorInvalidMaxForwardSpeed
+	}
+
+	// Check if the max backward speed is valid
+	if maxBackwardSpeed <= 0 || maxBackwardSpeed > 1 {
+		return nil, ErrorCodeESCMotorInvalidMaxBackwardSpeed
+	}
+
+	// Initialize the ESC motor with the provided parameters
+	handler := &DShotHandler{
+		transmitter:        transmitter,
+		protocol:           protocol,
+		afterSetSpeedFunc:  afterSetSpeedFunc,
+		isMovementEnabled:  isMovementEnabled,
+		isPolarityInverted: isPolarityInverted,
+		maxForwardSpeed:    maxForwardSpeed,
+		maxBackwardSpeed:   maxBackwardSpeed,
+		throttle:           DShotThrottleDisarmed,
+		logger:             logger,
+	}
+
+	// Stop the motor initially
+	_ = handler.Stop()
+
+	return handler, tinygoerrors.ErrorCodeNil
+}
+
+// speedToThrottle maps a speed onto the DShot throttle value in 3D mode
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed)
+// direction: Physical direction of the motor
+//
+// Returns:
+//
+// The DShot throttle value, DShotThrottleDisarmed for a zero speed
+func speedToThrottle(speed float64, direction Direction) uint16 {
+	if speed == 0 || direction == DirectionStop {
+		return DShotThrottleDisarmed
+	}
+	if direction == DirectionForward {
+		return DShot3DForwardThrottleMin + uint16(float64(dshot3DThrottleSpan)*speed)
+	}
+	return DShotThrottleMin + uint16(float64(dshot3DThrottleSpan)*speed)
+}
+
+// transmit sends the frame for a throttle value
+//
+// Parameters:
+//
+// throttle: The DShot throttle value
+//
+// Returns:
+//
+// An error if the frame could not be transmitted, otherwise nil
+func (h *DShotHandler) transmit(throttle uint16) tinygoerrors.ErrorCode {
+	if err := h.transmitter.Transmit(EncodeDShotFrame(throttle, h.isTelemetryRequested)); err != nil {
+		return ErrorCodeESCMotorFailedToTransmitDShotFrame
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// Refresh re-sends the frame for the current throttle value. DShot ESCs expect a continuous stream of frames, so it
+// must be called periodically from the control loop.
+//
+// Returns:
+//
+// An error if the frame could not be transmitted, otherwise nil
+func (h *DShotHandler) Refresh() tinygoerrors.ErrorCode {
+	return h.transmit(h.throttle)
+}
+
+// SetTelemetryRequested sets whether the frames request telemetry from the ESC.
+//
+// Parameters:
+//
+// isTelemetryRequested: Whether the ESC should send telemetry back
+func (h *DShotHandler) SetTelemetryRequested(isTelemetryRequested bool) {
+	h.isTelemetryRequested = isTelemetryRequested
+}
+
+// GetThrottle returns the DShot throttle value currently transmitted.
+//
+// Returns:
+//
+// The 11-bit throttle value
+func (h *DShotHandler) GetThrottle() uint16 {
+	return h.throttle
+}
+
+// GetProtocol returns the DShot protocol bit rate used by the transmitter.
+//
+// Returns:
+//
+// The DShot protocol
+func (h *DShotHandler) GetProtocol() DShotProtocol {
+	return h.protocol
+}
+
+// SetSpeed sets the ESC motor speed. In 3D mode the ESC handles the reversal, so no direction-change delays apply.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DShotHandler) SetSpeed(
+	speed float64,
+	direction Direction,
+) tinygoerrors.ErrorCode {
+	// Check if the is polarity inverted
+	if h.isPolarityInverted {
+		direction = direction.InvertedDirection()
+	}
+
+	// Check if the speed is within the valid range
+	if speed < 0 || speed > 1 {
+		return ErrorCodeESCMotorSpeedOutOfRange
+	}
+
+	// Get the signed speed based on the direction
+	var signedSpeed float64
+	switch direction {
+	case DirectionStop:
+		speed = 0
+	case DirectionForward:
+		signedSpeed = speed
+	case DirectionBackward:
+		signedSpeed = -speed
+	default:
+		return ErrorCodeESCMotorUnknownDirection
+	}
+
+	// Transmit the throttle value if movement is enabled, otherwise the disarmed value
+	throttle := speedToThrottle(speed, direction)
+	if h.isMovementEnabled != nil && !h.isMovementEnabled() {
+		throttle = DShotThrottleDisarmed
+	}
+	if errCode := h.transmit(throttle); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Update the state only once the frame has been transmitted
+	h.speed = signedSpeed
+	h.direction = direction
+	h.throttle = throttle
+
+	// Log the speed change
+	if h.logger != nil {
+		switch direction {
+		case DirectionStop:
+			h.logger.AddMessage(
+				stopPrefix,
+				true,
+			)
+			h.logger.Debug()
+		case DirectionForward:
+			h.logger.AddMessageWithFloat64(
+				setSpeedForwardPrefix,
+				speed,
+				Float64Precision,
+				true,
+				true,
+			)
+			h.logger.Debug()
+		case DirectionBackward:
+			h.logger.AddMessageWithFloat64(
+				setSpeedBackwardPrefix,
+				speed,
+				Float64Precision,
+				true,
+				true,
+			)
+			h.logger.Debug()
+		}
+	}
+
+	// Call the after set speed function if provided
+	if h.afterSetSpeedFunc != nil {
+		h.afterSetSpeedFunc(h.speed)
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// GetSpeed returns the current speed of the ESC motor.
+//
+// Returns:
+//
+// The current speed, positive when moving forward and negative when moving backward
+func (h *DShotHandler) GetSpeed() float64 {
+	return h.speed
+}
+
+// Stop sets the ESC motor speed to 0 (stop) by transmitting the disarmed throttle value.
+//
+// Returns:
+//
+// An error if the speed could not be set to 0, otherwise nil.
+func (h *DShotHandler) Stop() tinygoerrors.ErrorCode {
+	return h.SetSpeed(0, DirectionStop)
+}
+
+// SetSpeedForward sets the ESC motor speed forward.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxForwardSpeed (full forward).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DShotHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	// Check if the speed is within the valid range
+	if speed < 0 {
+		speed = 0
+	}
+	if speed > h.maxForwardSpeed {
+		speed = h.maxForwardSpeed
+	}
+	return h.SetSpeed(speed, DirectionForward)
+}
+
+// SetSpeedBackward sets the ESC motor speed backward.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxBackwardSpeed (full backward).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DShotHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
+	// Check if the speed is within the valid range
+	if speed < 0 {
+		speed = 0
+	}
+	if speed > h.maxBackwardSpeed {
+		speed = h.maxBackwardSpeed
+	}
+	return h.SetSpeed(speed, DirectionBackward)
+}
+
+// IsStopped returns whether the motor is stopped, transmitting the disarmed throttle value.
+//
+// Returns:
+//
+// True if the motor is stopped, otherwise false
+func (h *DShotHandler) IsStopped() bool {
+	return h.throttle == DShotThrottleDisarmed
+}
+
+// IsMoving returns whether the motor is being driven with a throttle value.
+//
+// Returns:
+//
+// True if the motor is not stopped, otherwise false
+func (h *DShotHandler) IsMoving() bool {
+	return !h.IsStopped()
+}
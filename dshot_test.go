@@ -0,0 +1,187 @@
+package tinygo_escmotor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// steppingClock is the fake clock moving forward by a nanosecond on every read, so the busy-waits return
+	steppingClock struct {
+		*fakeClock
+	}
+
+	// recordingTransmitter is the DShotTransmitter recording the frames, failing while err is set
+	recordingTransmitter struct {
+		frames []uint16
+		err    error
+	}
+
+	// pinEdge is an edge driven on the signal line by the pin DShot transmitter
+	pinEdge struct {
+		isHigh bool
+		at     time.Time
+	}
+)
+
+// Now moves the fake time forward by a nanosecond and returns it
+func (c steppingClock) Now() time.Time {
+	return c.Advance(time.Nanosecond)
+}
+
+func (r *recordingTransmitter) Transmit(frame uint16) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.frames = append(r.frames, frame)
+	return nil
+}
+
+func TestEncodeDShotFrame(t *testing.T) {
+	tests := []struct {
+		name                 string
+		throttle             uint16
+		isTelemetryRequested bool
+		want                 uint16
+	}{
+		{name: "disarmed", throttle: DShotThrottleDisarmed, want: 0x0000},
+		{name: "throttle", throttle: 1046, want: 0x82C6},
+		{name: "telemetry", throttle: 1046, isTelemetryRequested: true, want: 0x82D7},
+		{name: "max throttle", throttle: DShotThrottleMax, want: 0xFFEE},
+		{name: "throttle above 11 bits", throttle: DShotThrottleMax + 1 + 1046, want: 0x82C6},
+	}
+	for _, tt := range tests {
+		if got := EncodeDShotFrame(tt.throttle, tt.isTelemetryRequested); got != tt.want {
+			t.Errorf("EncodeDShotFrame(%d, %v) = %#04x, want %#04x", tt.throttle, tt.isTelemetryRequested, got, tt.want)
+		}
+	}
+
+	// The CRC makes the nibbles of every frame cancel out, and the telemetry bit sits right above it
+	for throttle := uint16(0); throttle <= DShotThrottleMax; throttle++ {
+		for _, isTelemetryRequested := range []bool{false, true} {
+			frame := EncodeDShotFrame(throttle, isTelemetryRequested)
+			if crc := (frame ^ frame>>4 ^ frame>>8 ^ frame>>12) & 0x0F; crc != 0 {
+				t.Fatalf("EncodeDShotFrame(%d, %v) = %#04x has a wrong CRC", throttle, isTelemetryRequested, frame)
+			}
+			if got := frame&(1<<4) != 0; got != isTelemetryRequested {
+				t.Fatalf("EncodeDShotFrame(%d, %v) telemetry bit = %v", throttle, isTelemetryRequested, got)
+			}
+			if got := frame >> 5; got != throttle {
+				t.Fatalf("EncodeDShotFrame(%d, %v) throttle = %d", throttle, isTelemetryRequested, got)
+			}
+		}
+	}
+}
+
+func TestSpeedToThrottle(t *testing.T) {
+	tests := []struct {
+		name      string
+		speed     float64
+		direction Direction
+		want      uint16
+	}{
+		{name: "stop", speed: 0.5, direction: DirectionStop, want: DShotThrottleDisarmed},
+		{name: "zero speed", speed: 0, direction: DirectionForward, want: DShotThrottleDisarmed},
+		{name: "slowest backward", speed: 1e-9, direction: DirectionBackward, want: 48},
+		{name: "full backward", speed: 1, direction: DirectionBackward, want: 1047},
+		{name: "slowest forward", speed: 1e-9, direction: DirectionForward, want: 1048},
+		{name: "full forward", speed: 1, direction: DirectionForward, want: 2047},
+	}
+	for _, tt := range tests {
+		if got := speedToThrottle(tt.speed, tt.direction); got != tt.want {
+			t.Errorf("%s: speedToThrottle(%v, %v) = %d, want %d", tt.name, tt.speed, tt.direction, got, tt.want)
+		}
+	}
+}
+
+func TestDShotHandlerKeepsStateOnTransmitFailure(t *testing.T) {
+	transmitter := &recordingTransmitter{}
+	h, errCode := NewDShotHandler(transmitter, DShotProtocol300, nil, nil, false, 1, 1, nil)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewDShotHandler() = %v", errCode)
+	}
+	mustSucceed(t, h.SetSpeedForward(0.5))
+	throttle := h.GetThrottle()
+
+	// A frame that could not be transmitted leaves the getters on the last transmitted command
+	transmitter.err = errors.New("transmit failure")
+	if errCode = h.SetSpeedBackward(1); errCode != ErrorCodeESCMotorFailedToTransmitDShotFrame {
+		t.Fatalf("SetSpeedBackward() = %v, want ErrorCodeESCMotorFailedToTransmitDShotFrame", errCode)
+	}
+	if h.GetSpeed() != 0.5 || h.GetThrottle() != throttle {
+		t.Fatalf("speed %v and throttle %d after the failure, want 0.5 and %d", h.GetSpeed(), h.GetThrottle(), throttle)
+	}
+}
+
+func TestPinDShotTransmitterBitTimings(t *testing.T) {
+	clock := steppingClock{newFakeClock()}
+	var edges []pinEdge
+	transmitter, errCode := newPinDShotTransmitter(
+		func(isHigh bool) {
+			edges = append(edges, pinEdge{isHigh: isHigh, at: clock.fakeClock.Now()})
+		},
+		clock,
+		DShotProtocol150,
+	)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("newPinDShotTransmitter() = %v", errCode)
+	}
+
+	frame := EncodeDShotFrame(1046, true)
+	if err := transmitter.Transmit(frame); err != nil {
+		t.Fatalf("Transmit() = %v", err)
+	}
+	if len(edges) != 2*dshotFrameBits {
+		t.Fatalf("Transmit() drove %d edges, want %d", len(edges), 2*dshotFrameBits)
+	}
+
+	// Every bit starts one bit period after the previous one, high for 3/4 of it for a one and 3/8 for a zero. The
+	// clock moves on every read, so the first edge is a nanosecond late.
+	bitPeriod := time.Second / 150000
+	start := edges[0].at.Add(-time.Nanosecond)
+	for i := 0; i < dshotFrameBits; i++ {
+		rise, fall := edges[2*i], edges[2*i+1]
+		if !rise.isHigh || fall.isHigh {
+			t.Fatalf("bit %d drove %v then %v, want high then low", i, rise.isHigh, fall.isHigh)
+		}
+		bitStart := start.Add(time.Duration(i) * bitPeriod)
+		if i > 0 && !rise.at.Equal(bitStart) {
+			t.Fatalf("bit %d starts at %v, want %v", i, rise.at.Sub(start), bitStart.Sub(start))
+		}
+		want := bitPeriod * 3 / 8
+		if frame&(1<<(dshotFrameBits-1-i)) != 0 {
+			want = bitPeriod * 3 / 4
+		}
+		if got := fall.at.Sub(bitStart); got != want {
+			t.Fatalf("bit %d high for %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestPinDShotTransmitterReportsMissedTiming(t *testing.T) {
+	clock := steppingClock{newFakeClock()}
+	transmitter, errCode := newPinDShotTransmitter(
+		func(isHigh bool) {
+			// Stall like an interrupt firing mid-frame
+			clock.Advance(time.Millisecond)
+		},
+		clock,
+		DShotProtocol600,
+	)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("newPinDShotTransmitter() = %v", errCode)
+	}
+	if err := transmitter.Transmit(EncodeDShotFrame(1046, false)); !errors.Is(err, errDShotBitTimingMissed) {
+		t.Fatalf("Transmit() = %v, want errDShotBitTimingMissed", err)
+	}
+}
+
+func TestNewPinDShotTransmitterRejectsUnknownProtocol(t *testing.T) {
+	_, errCode := newPinDShotTransmitter(func(bool) {}, realClock{}, DShotProtocolNil)
+	if errCode != ErrorCodeESCMotorInvalidDShotProtocol {
+		t.Fatalf("newPinDShotTransmitter() = %v, want ErrorCodeESCMotorInvalidDShotProtocol", errCode)
+	}
+}
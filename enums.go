@@ -1,8 +1,34 @@
 package tinygo_escmotor
 
+import (
+	"math"
+)
+
 type (
 	// Direction is an enum to represent the different motor directions for the vehicle.
 	Direction uint8
+
+	// Protocol is an enum to represent the different analog ESC protocol timing presets.
+	Protocol uint8
+
+	// DShotProtocol is an enum to represent the different DShot digital protocol bit rates.
+	DShotProtocol uint8
+
+	// ThrottleCurve is an enum to represent the different easing curves applied to the speed before mapping it onto the
+	// pulse width.
+	ThrottleCurve uint8
+
+	// EventType is an enum to represent the different state-change events emitted by the handler.
+	EventType uint8
+
+	// LogCategory is an enum to represent the different categories of the log messages of the handler.
+	LogCategory uint8
+
+	// LogLevel is an enum to represent the different logger methods the log messages can be routed to.
+	LogLevel uint8
+
+	// StopPulse is an enum to represent the different pulse widths driven when the motor is stopped.
+	StopPulse uint8
 )
 
 const (
@@ -10,13 +36,84 @@ const (
 	DirectionForward
 	DirectionBackward
 	DirectionStop
+	DirectionBrake
+	DirectionCoast
+)
+
+const (
+	EventTypeNil EventType = iota
+	EventTypeSpeedChanged
+	EventTypeDirectionChanged
+	EventTypeRampComplete
+	EventTypeFault
+	EventTypeArmed
 )
 
+const (
+	LogCategoryPulseStep LogCategory = iota
+	LogCategorySpeed
+	LogCategoryStop
+
+	// logCategoryEnd marks the end of the log categories, new categories must be added before it
+	logCategoryEnd
+)
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarning
+	LogLevelError
+	LogLevelNone
+)
+
+// DirectionFromSpeed returns the direction of a signed speed.
+//
+// Parameters:
+//
+// signed: The signed speed, positive for forward and negative for backward
+//
+// Returns:
+//
+// DirectionForward for a positive speed, DirectionBackward for a negative speed, otherwise DirectionStop
+func DirectionFromSpeed(signed float64) Direction {
+	if signed > 0 {
+		return DirectionForward
+	}
+	if signed < 0 {
+		return DirectionBackward
+	}
+	return DirectionStop
+}
+
+// IsValid returns whether the direction is one of the Direction constants other than DirectionNil.
+func (d Direction) IsValid() bool {
+	switch d {
+	case DirectionForward, DirectionBackward, DirectionStop, DirectionBrake, DirectionCoast:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsForward returns whether the direction is DirectionForward.
+func (d Direction) IsForward() bool {
+	return d == DirectionForward
+}
+
+// IsBackward returns whether the direction is DirectionBackward.
+func (d Direction) IsBackward() bool {
+	return d == DirectionBackward
+}
+
 // InvertedDirection returns the inverted direction.
 func (d Direction) InvertedDirection() Direction {
 	switch d {
 	case DirectionStop:
 		return DirectionStop
+	case DirectionBrake:
+		return DirectionBrake
+	case DirectionCoast:
+		return DirectionCoast
 	case DirectionForward:
 		return DirectionBackward
 	case DirectionBackward:
@@ -25,3 +122,155 @@ func (d Direction) InvertedDirection() Direction {
 		return DirectionNil
 	}
 }
+
+var (
+	// directionNilBytes is the name of DirectionNil
+	directionNilBytes = []byte("Nil")
+
+	// directionForwardBytes is the name of DirectionForward
+	directionForwardBytes = []byte("Forward")
+
+	// directionBackwardBytes is the name of DirectionBackward
+	directionBackwardBytes = []byte("Backward")
+
+	// directionStopBytes is the name of DirectionStop
+	directionStopBytes = []byte("Stop")
+
+	// directionBrakeBytes is the name of DirectionBrake
+	directionBrakeBytes = []byte("Brake")
+
+	// directionCoastBytes is the name of DirectionCoast
+	directionCoastBytes = []byte("Coast")
+
+	// directionUnknownBytes is the name of the values outside the Direction constants
+	directionUnknownBytes = []byte("Unknown")
+)
+
+// Bytes returns the name of the direction as a preallocated byte slice, which must not be modified.
+func (d Direction) Bytes() []byte {
+	switch d {
+	case DirectionNil:
+		return directionNilBytes
+	case DirectionForward:
+		return directionForwardBytes
+	case DirectionBackward:
+		return directionBackwardBytes
+	case DirectionStop:
+		return directionStopBytes
+	case DirectionBrake:
+		return directionBrakeBytes
+	case DirectionCoast:
+		return directionCoastBytes
+	default:
+		return directionUnknownBytes
+	}
+}
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	return string(d.Bytes())
+}
+
+const (
+	DShotProtocolNil DShotProtocol = iota
+	DShotProtocol150
+	DShotProtocol300
+	DShotProtocol600
+)
+
+// BitRate returns the bit rate of the DShot protocol.
+func (p DShotProtocol) BitRate() uint32 {
+	switch p {
+	case DShotProtocol150:
+		return 150000
+	case DShotProtocol300:
+		return 300000
+	case DShotProtocol600:
+		return 600000
+	default:
+		return 0
+	}
+}
+
+const (
+	ProtocolNil Protocol = iota
+
+	// ProtocolStandard is the standard servo PWM, 1000-2000µs pulses (1500µs neutral) at 50Hz
+	ProtocolStandard
+
+	// ProtocolOneShot125 is OneShot125, 125-250µs pulses (187.5µs neutral) at 2kHz
+	ProtocolOneShot125
+
+	// ProtocolOneShot42 is OneShot42, 42-84µs pulses (63µs neutral) at 8kHz
+	ProtocolOneShot42
+
+	// ProtocolMultishot is Multishot, 5-25µs pulses (15µs neutral) at 32kHz
+	ProtocolMultishot
+)
+
+// Frequency returns the frame rate of the protocol.
+func (p Protocol) Frequency() uint16 {
+	switch p {
+	case ProtocolStandard:
+		return 50
+	case ProtocolOneShot125:
+		return 2000
+	case ProtocolOneShot42:
+		return 8000
+	case ProtocolMultishot:
+		return 32000
+	default:
+		return 0
+	}
+}
+
+// PulseWidths returns the min, neutral and max pulse widths of the protocol in nanoseconds.
+func (p Protocol) PulseWidths() (uint32, uint32, uint32) {
+	switch p {
+	case ProtocolStandard:
+		return 1000000, 1500000, 2000000
+	case ProtocolOneShot125:
+		return 125000, 187500, 250000
+	case ProtocolOneShot42:
+		return 42000, 63000, 84000
+	case ProtocolMultishot:
+		return 5000, 15000, 25000
+	default:
+		return 0, 0, 0
+	}
+}
+
+const (
+	StopPulseNeutral StopPulse = iota
+	StopPulseMin
+)
+
+// IsValid returns whether the stop pulse is one of the StopPulse constants.
+func (s StopPulse) IsValid() bool {
+	return s == StopPulseNeutral || s == StopPulseMin
+}
+
+const (
+	ThrottleCurveLinear ThrottleCurve = iota
+	ThrottleCurveExpo
+	ThrottleCurveCubic
+	ThrottleCurveCustom
+)
+
+const (
+	// ThrottleCurveExpoFactor is the growth factor of the exponential throttle curve
+	ThrottleCurveExpoFactor = 3.0
+)
+
+// Apply applies the throttle curve to a speed between 0 and 1. The custom curve is applied by the handler, so it is
+// returned unchanged here.
+func (c ThrottleCurve) Apply(input float64) float64 {
+	switch c {
+	case ThrottleCurveExpo:
+		return (math.Exp(ThrottleCurveExpoFactor*input) - 1) / (math.Exp(ThrottleCurveExpoFactor) - 1)
+	case ThrottleCurveCubic:
+		return input * input * input
+	default:
+		return input
+	}
+}
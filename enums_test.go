@@ -0,0 +1,25 @@
+package tinygo_escmotor
+
+import (
+	"testing"
+)
+
+func TestDirectionIsValid(t *testing.T) {
+	tests := []struct {
+		direction Direction
+		want      bool
+	}{
+		{direction: DirectionNil, want: false},
+		{direction: DirectionForward, want: true},
+		{direction: DirectionBackward, want: true},
+		{direction: DirectionStop, want: true},
+		{direction: DirectionBrake, want: true},
+		{direction: DirectionCoast, want: true},
+		{direction: Direction(99), want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.direction.IsValid(); got != tt.want {
+			t.Errorf("Direction(%d).IsValid() = %v, want %v", tt.direction, got, tt.want)
+		}
+	}
+}
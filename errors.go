@@ -1,6 +1,8 @@
 package tinygo_escmotor
 
 import (
+	"errors"
+
 	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
 )
 
@@ -21,4 +23,173 @@ const (
 	ErrorCodeESCMotorInvalidMaxForwardSpeed
 	ErrorCodeESCMotorInvalidMaxBackwardSpeed
 	ErrorCodeESCMotorFailedToGetPWMChannel
+	ErrorCodeESCMotorInvalidDelayScale
+	ErrorCodeESCMotorInvalidConfigData
+	ErrorCodeESCMotorNeutralLocked
+	ErrorCodeESCMotorInvalidStartOffset
+	ErrorCodeESCMotorBackwardNotSupported
+	ErrorCodeESCMotorCalibrationWhileArmed
+	ErrorCodeESCMotorNotArmed
+	ErrorCodeESCMotorNilDShotTransmitter
+	ErrorCodeESCMotorInvalidDShotProtocol
+	ErrorCodeESCMotorFailedToTransmitDShotFrame
+	ErrorCodeESCMotorUnsupportedProtocol
+	ErrorCodeESCMotorClosed
+	ErrorCodeESCMotorBrakeModeDisabled
+	ErrorCodeESCMotorFailedToSetDuty
+	ErrorCodeESCMotorInvalidDeadband
+	ErrorCodeESCMotorMode3DDisabled
+	ErrorCodeESCMotorInvalidMotorPoles
+	ErrorCodeESCMotorInvalidCurrentLimit
+	ErrorCodeESCMotorInvalidThermalDerate
+	ErrorCodeESCMotorInvalidSoftStartRate
+	ErrorCodeESCMotorInvalidPulseStep
+	ErrorCodeESCMotorRawPulseOutOfRange
+	ErrorCodeESCMotorInvalidDirectionDelay
+	ErrorCodeESCMotorInvalidMaxRampTime
+	ErrorCodeESCMotorNeutralTrimNotSupported
+	ErrorCodeESCMotorNilRPMSource
+	ErrorCodeESCMotorMovementDisabled
+	ErrorCodeESCMotorInvalidStopPulse
+	ErrorCodeESCMotorInvalidMinEffectiveSpeed
+	ErrorCodeESCMotorInvalidStopRampDuration
+	ErrorCodeESCMotorInvalidInputSmoothing
+	ErrorCodeESCMotorInvalidRateLimitInterval
+	ErrorCodeESCMotorInvalidBoostThreshold
+	ErrorCodeESCMotorBoostNotEnabled
+	ErrorCodeESCMotorSelfTestFailed
+	ErrorCodeESCMotorInvalidIdleKeepAlive
+	ErrorCodeESCMotorNotStopped
+	ErrorCodeESCMotorInvalidStateData
+	ErrorCodeESCMotorDuplicateHandler
+
+	// errorCodeESCMotorEnd marks the end of the ESC motor error codes, new codes must be added before it
+	errorCodeESCMotorEnd
+)
+
+// GetErrorCodeRange returns the range of the ESC motor error codes.
+//
+// Returns:
+//
+// The first and the last ESC motor error codes, both inclusive
+func GetErrorCodeRange() (tinygoerrors.ErrorCode, tinygoerrors.ErrorCode) {
+	return ErrorCodeESCMotorFailedToConfigurePWM, errorCodeESCMotorEnd - 1
+}
+
+// IsErrorCode checks if an error code belongs to the ESC motor error codes.
+//
+// Parameters:
+//
+// code: The error code to check
+//
+// Returns:
+//
+// True if the error code is within the ESC motor error codes range, otherwise false
+func IsErrorCode(code tinygoerrors.ErrorCode) bool {
+	first, last := GetErrorCodeRange()
+	return code >= first && code <= last
+}
+
+// TranslateErrorCode remaps an ESC motor error code onto a caller-supplied base, so several ESC subsystems can report
+// distinguishable codes.
+//
+// Parameters:
+//
+// code: The error code returned by this package
+// base: The starting number to remap the ESC motor error codes onto
+//
+// Returns:
+//
+// The remapped error code, or the same code if it is not an ESC motor error code
+func TranslateErrorCode(code tinygoerrors.ErrorCode, base uint16) tinygoerrors.ErrorCode {
+	// Check if the code belongs to this package
+	if !IsErrorCode(code) {
+		return code
+	}
+	return tinygoerrors.ErrorCode(base) + code - ErrorCodeESCMotorFailedToConfigurePWM
+}
+
+var (
+	// errorCodeESCMotorMessages are the messages of the ESC motor error codes, in the same order as the codes
+	errorCodeESCMotorMessages = [errorCodeESCMotorEnd - ErrorCodeESCMotorFailedToConfigurePWM][]byte{
+		[]byte("failed to configure the PWM"),
+		[]byte("PWM frequency is zero"),
+		[]byte("speed is out of range"),
+		[]byte("handler is nil"),
+		[]byte("invalid neutral pulse width"),
+		[]byte("invalid minimum pulse width"),
+		[]byte("invalid maximum pulse width"),
+		[]byte("unknown direction"),
+		[]byte("invalid maximum forward speed"),
+		[]byte("invalid maximum backward speed"),
+		[]byte("failed to get the PWM channel"),
+		[]byte("invalid delay scale"),
+		[]byte("invalid config data"),
+		[]byte("motor is locked at neutral"),
+		[]byte("invalid start offset"),
+		[]byte("backward is not supported by unidirectional motors"),
+		[]byte("calibration is not allowed while armed"),
+		[]byte("motor is not armed"),
+		[]byte("DShot transmitter is nil"),
+		[]byte("invalid DShot protocol"),
+		[]byte("failed to transmit the DShot frame"),
+		[]byte("unsupported protocol"),
+		[]byte("handler is closed"),
+		[]byte("brake mode is disabled"),
+		[]byte("failed to set the duty cycle"),
+		[]byte("invalid deadband"),
+		[]byte("3D mode is disabled"),
+		[]byte("invalid number of motor poles"),
+		[]byte("invalid current limit"),
+		[]byte("invalid thermal derating temperatures"),
+		[]byte("invalid soft start rate"),
+		[]byte("invalid pulse step"),
+		[]byte("raw pulse width is out of range"),
+		[]byte("invalid direction-change delay"),
+		[]byte("invalid maximum ramp time"),
+		[]byte("neutral trim is not supported by unidirectional motors"),
+		[]byte("RPM source is nil"),
+		[]byte("movement disabled mid-ramp"),
+		[]byte("invalid stop pulse"),
+		[]byte("invalid minimum effective speed"),
+		[]byte("invalid stop ramp duration"),
+		[]byte("invalid input smoothing factor"),
+		[]byte("invalid rate limit interval"),
+		[]byte("invalid boost threshold"),
+		[]byte("boost not enabled"),
+		[]byte("self-test failed, PWM read-back mismatch"),
+		[]byte("invalid idle keep-alive interval"),
+		[]byte("motor is not stopped"),
+		[]byte("invalid handler state data"),
+		[]byte("handler listed more than once"),
+	}
+
+	// errNilPWM is the error returned by the default duty writer when the PWM is nil
+	errNilPWM = errors.New("nil PWM")
+
+	// errPulseOutOfPeriod is the error returned by the default duty writer when the pulse width exceeds the period
+	errPulseOutOfPeriod = errors.New("pulse width exceeds the PWM period")
+
+	// errDShotBitTimingMissed is the error returned by the pin DShot transmitter when a bit overran its period
+	errDShotBitTimingMissed = errors.New("DShot bit timing missed")
+
+	// errorCodeESCMotorUnknownMessage is the message of the error codes outside the ESC motor error codes range
+	errorCodeESCMotorUnknownMessage = []byte("unknown ESC motor error")
 )
+
+// ErrorCodeToString returns the human-readable message of an ESC motor error code.
+//
+// Parameters:
+//
+// code: The error code to describe
+//
+// Returns:
+//
+// The message of the error code, or a generic message if it is not an ESC motor error code
+func ErrorCodeToString(code tinygoerrors.ErrorCode) []byte {
+	// Check if the code belongs to this package and has a message
+	if !IsErrorCode(code) || errorCodeESCMotorMessages[code-ErrorCodeESCMotorFailedToConfigurePWM] == nil {
+		return errorCodeESCMotorUnknownMessage
+	}
+	return errorCodeESCMotorMessages[code-ErrorCodeESCMotorFailedToConfigurePWM]
+}
@@ -0,0 +1,54 @@
+package tinygo_escmotor
+
+import (
+	"testing"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+func TestEveryErrorCodeHasAMessage(t *testing.T) {
+	first, last := GetErrorCodeRange()
+	seen := make(map[string]tinygoerrors.ErrorCode)
+	for code := first; code <= last; code++ {
+		message := string(ErrorCodeToString(code))
+		if message == string(errorCodeESCMotorUnknownMessage) {
+			t.Fatalf("error code %d has no message", code)
+		}
+		if previous, ok := seen[message]; ok {
+			t.Fatalf("error codes %d and %d share the message %q", previous, code, message)
+		}
+		seen[message] = code
+	}
+
+	// The messages appended last stay aligned with their codes
+	tests := []struct {
+		code tinygoerrors.ErrorCode
+		want string
+	}{
+		{code: ErrorCodeESCMotorFailedToConfigurePWM, want: "failed to configure the PWM"},
+		{code: ErrorCodeESCMotorNotStopped, want: "motor is not stopped"},
+		{code: ErrorCodeESCMotorInvalidStateData, want: "invalid handler state data"},
+		{code: ErrorCodeESCMotorDuplicateHandler, want: "handler listed more than once"},
+		{code: first - 1, want: string(errorCodeESCMotorUnknownMessage)},
+		{code: last + 1, want: string(errorCodeESCMotorUnknownMessage)},
+	}
+	for _, tt := range tests {
+		if got := string(ErrorCodeToString(tt.code)); got != tt.want {
+			t.Errorf("ErrorCodeToString(%d) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateErrorCodeRoundTrip(t *testing.T) {
+	const base = 9000
+	first, last := GetErrorCodeRange()
+	for code := first; code <= last; code++ {
+		translated := TranslateErrorCode(code, base)
+		if back := translated - base + first; back != code {
+			t.Fatalf("TranslateErrorCode(%d) = %d does not map back", code, translated)
+		}
+	}
+	if got := TranslateErrorCode(tinygoerrors.ErrorCodeNil, base); got != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("TranslateErrorCode(nil) = %d, want nil", got)
+	}
+}
@@ -12,5 +12,7 @@ type (
 		SetSpeed(speed float64, direction Direction) tinygoerrors.ErrorCode
 		SetSpeedForward(speed float64) tinygoerrors.ErrorCode
 		SetSpeedBackward(speed float64) tinygoerrors.ErrorCode
+		IsStopped() bool
+		IsMoving() bool
 	}
 )
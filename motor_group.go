@@ -0,0 +1,277 @@
+package tinygo_escmotor
+
+import (
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// MotorGroup is the implementation to drive several ESC motors in lockstep, so every ramp of the group reaches its
+	// target on the same period boundary.
+	MotorGroup struct {
+		handlers []*DefaultHandler
+	}
+)
+
+const (
+	// OpSetSpeedAll is the operation label reported when SetSpeedAll fails
+	OpSetSpeedAll = "SetSpeedAll"
+
+	// NoFailedMotor is the motor index reported by the group when no motor failed
+	NoFailedMotor = -1
+)
+
+// NewMotorGroup creates a new instance of MotorGroup
+//
+// Parameters:
+//
+// handlers: The handlers of the motors of the group
+//
+// Returns:
+//
+// An instance of MotorGroup and an error if any of the handlers is nil or listed more than once, which would deadlock
+// the group commands locking every handler
+func NewMotorGroup(handlers ...*DefaultHandler) (*MotorGroup, tinygoerrors.ErrorCode) {
+	for i, handler := range handlers {
+		// Check if the handler is nil
+		if handler == nil {
+			return nil, ErrorCodeESCMotorNilHandler
+		}
+
+		// Check if the handler is already in the group
+		for _, previous := range handlers[:i] {
+			if previous == handler {
+				return nil, ErrorCodeESCMotorDuplicateHandler
+			}
+		}
+	}
+
+	return &MotorGroup{
+		handlers: handlers,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// GetHandlers returns the handlers of the motors of the group.
+//
+// Returns:
+//
+// The handlers of the motors of the group
+func (g *MotorGroup) GetHandlers() []*DefaultHandler {
+	return g.handlers
+}
+
+// SetSpeedAll sets the speed of every motor of the group, stepping all the pulse widths once per period so they reach
+// their targets together. The motor with the most steps to travel dictates the duration of the ramp. The motors that
+// change direction first reach neutral together and wait for the longest of their direction-change delays.
+//
+// Every handler of the group is locked for the whole command in the order of the group, so the groups sharing handlers
+// must list them in the same order.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motors.
+//
+// Returns:
+//
+// The index of the motor that failed, NoFailedMotor if none did, and its error, otherwise nil
+func (g *MotorGroup) SetSpeedAll(speed float64, direction Direction) (int, tinygoerrors.ErrorCode) {
+	// Lock every handler for the whole command
+	for _, handler := range g.handlers {
+		handler.lockCommand()
+		defer handler.commandMutex.Unlock()
+	}
+
+	// Resolve the speed command of every motor before driving any of them
+	cmds := make([]speedCommand, len(g.handlers))
+	for i, handler := range g.handlers {
+		// Clamp the speed to the max speed of the motor
+		motorSpeed := speed
+		if direction == DirectionForward && motorSpeed > handler.maxForwardSpeed {
+			motorSpeed = handler.maxForwardSpeed
+		} else if direction == DirectionBackward && motorSpeed > handler.maxBackwardSpeed {
+			motorSpeed = handler.maxBackwardSpeed
+		}
+
+		cmd, errCode := handler.resolveSpeedCommand(motorSpeed, direction)
+		if errCode != tinygoerrors.ErrorCodeNil {
+			return i, handler.reportError(errCode, OpSetSpeedAll)
+		}
+		cmds[i] = cmd
+	}
+
+	// Store the speeds and pick the motors that have to go through neutral first
+	isDriven := make([]bool, len(g.handlers))
+	targets := make([]uint32, len(g.handlers))
+	isDetourRequired := false
+	var delay time.Duration
+	for i, handler := range g.handlers {
+		handler.applySpeedCommand(cmds[i])
+		if handler.isMovementDisabled() || (handler.isPulseWritten && handler.pulse == cmds[i].pulse) {
+			continue
+		}
+		isDriven[i] = true
+		targets[i] = handler.pulse
+		if handler.isNeutralDetourRequired(cmds[i].direction) {
+			handler.countNeutralDetour()
+			targets[i] = handler.neutralPulseWidth
+			isDetourRequired = true
+		}
+	}
+
+	// Ramp the motors that change direction to neutral
+	if isDetourRequired {
+		if i, errCode := g.rampTogether(isDriven, targets); errCode != tinygoerrors.ErrorCodeNil {
+			return i, g.handlers[i].reportError(errCode, OpSetSpeedAll)
+		}
+	}
+
+	// Wait for the longest direction-change delay
+	for i, handler := range g.handlers {
+		if !isDriven[i] {
+			continue
+		}
+		if motorDelay := handler.directionChangeDelay(cmds[i].direction); motorDelay > delay {
+			delay = motorDelay
+		}
+	}
+	g.sleep(delay)
+
+	// Ramp every motor to its target
+	for i := range g.handlers {
+		targets[i] = cmds[i].pulse
+	}
+	failedIndex, errCode := g.rampTogether(isDriven, targets)
+
+	// Update the state of every driven motor
+	for i, handler := range g.handlers {
+		if isDriven[i] {
+			isDirectionChanged := handler.completeDirection(cmds[i].direction)
+			if errCode != tinygoerrors.ErrorCodeNil {
+				continue
+			}
+			handler.completeRamp(isDirectionChanged)
+		}
+		handler.finishSpeedCommand(cmds[i])
+	}
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return failedIndex, g.handlers[failedIndex].reportError(errCode, OpSetSpeedAll)
+	}
+
+	// Report the first non-fatal error, e.g. an engaged neutral lock
+	for i, handler := range g.handlers {
+		if cmds[i].errCode != tinygoerrors.ErrorCodeNil {
+			return i, handler.reportError(cmds[i].errCode, OpSetSpeedAll)
+		}
+	}
+	return NoFailedMotor, tinygoerrors.ErrorCodeNil
+}
+
+// StopAll stops every motor of the group in lockstep.
+//
+// Returns:
+//
+// The index of the motor that failed, NoFailedMotor if none did, and its error, otherwise nil
+func (g *MotorGroup) StopAll() (int, tinygoerrors.ErrorCode) {
+	return g.SetSpeedAll(0, DirectionStop)
+}
+
+// sleep pauses for the duration on the clock of the first motor of the group, the motors of a group are expected to
+// share the same source of time
+//
+// Parameters:
+//
+// duration: The duration to sleep
+func (g *MotorGroup) sleep(duration time.Duration) {
+	if len(g.handlers) == 0 || duration <= 0 {
+		return
+	}
+	g.handlers[0].clock.Sleep(duration)
+}
+
+// rampTogether ramps the pulse widths of the driven motors to their targets in the same number of steps, writing one
+// step of every motor per period
+//
+// Parameters:
+//
+// isDriven: Whether each motor is driven
+// targets: The target pulse width of each motor
+//
+// Returns:
+//
+// The index of the motor that failed, NoFailedMotor if none did, and its error, otherwise nil
+func (g *MotorGroup) rampTogether(isDriven []bool, targets []uint32) (int, tinygoerrors.ErrorCode) {
+	// Get the number of steps of the motor with the most to travel and the longest period
+	starts := make([]uint32, len(g.handlers))
+	var steps uint32
+	var periodDelay time.Duration
+	for i, handler := range g.handlers {
+		if !isDriven[i] {
+			continue
+		}
+		starts[i] = handler.pulse
+		handler.stateMutex.Lock()
+		handler.rampTarget = targets[i]
+		handler.stateMutex.Unlock()
+
+		// Get the number of steps of the motor, at least one if no pulse width has been written yet
+		motorSteps := handler.rampSteps(handler.pulse, targets[i], handler.softStartDuration)
+		if motorSteps == 0 && !handler.isPulseWritten {
+			motorSteps = 1
+		}
+		if motorSteps > steps {
+			steps = motorSteps
+		}
+		if handler.periodDelay > periodDelay {
+			periodDelay = handler.periodDelay
+		}
+	}
+
+	// Check if there is nothing to ramp
+	if steps == 0 {
+		return NoFailedMotor, tinygoerrors.ErrorCodeNil
+	}
+
+	// Record the duration of the ramp of every driven motor, even if it fails
+	start := g.handlers[0].clock.Now()
+	defer func() {
+		for i, handler := range g.handlers {
+			if isDriven[i] {
+				handler.recordRampDuration(start, starts[i] != targets[i])
+			}
+		}
+	}()
+
+	// Set the step of every motor to the even share of its distance
+	for i, handler := range g.handlers {
+		if isDriven[i] {
+			handler.stateMutex.Lock()
+			handler.rampStep = pulseDistance(starts[i], targets[i]) / steps
+			handler.stateMutex.Unlock()
+		}
+	}
+
+	// Write one step of every motor per period
+	for step := uint32(1); step <= steps; step++ {
+		for i, handler := range g.handlers {
+			if !isDriven[i] {
+				continue
+			}
+			if errCode := handler.writeRampPulseWidth(
+				interpolatePulseWidth(
+					starts[i],
+					targets[i],
+					step,
+					steps,
+				),
+			); errCode != tinygoerrors.ErrorCodeNil {
+				return i, errCode
+			}
+		}
+		if step < steps {
+			g.sleep(periodDelay)
+		}
+	}
+	return NoFailedMotor, tinygoerrors.ErrorCodeNil
+}
@@ -0,0 +1,159 @@
+package tinygo_escmotor
+
+import (
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+// newTestGroup creates a group of dry run handlers sharing a fake clock, each recording its pulse widths
+//
+// Parameters:
+//
+// t: The test
+// clock: The clock shared by the handlers
+// opts: The options of each handler
+//
+// Returns:
+//
+// The group and the recorders of the handlers
+func newTestGroup(t *testing.T, clock *fakeClock, opts ...[]Option) (*MotorGroup, []*pulseRecorder) {
+	t.Helper()
+	handlers := make([]*DefaultHandler, len(opts))
+	recorders := make([]*pulseRecorder, len(opts))
+	for i := range opts {
+		recorders[i] = &pulseRecorder{}
+		handlers[i] = newTestHandler(t, append([]Option{withClock(clock), recorders[i].option()}, opts[i]...)...)
+	}
+	group, errCode := NewMotorGroup(handlers...)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewMotorGroup() = %v", errCode)
+	}
+	return group, recorders
+}
+
+func TestMotorGroupRejectsDuplicateHandlers(t *testing.T) {
+	h := newTestHandler(t)
+	tests := []struct {
+		name     string
+		handlers []*DefaultHandler
+		want     tinygoerrors.ErrorCode
+	}{
+		{name: "distinct", handlers: []*DefaultHandler{h, newTestHandler(t)}, want: tinygoerrors.ErrorCodeNil},
+		{name: "nil", handlers: []*DefaultHandler{h, nil}, want: ErrorCodeESCMotorNilHandler},
+		{name: "duplicate", handlers: []*DefaultHandler{h, newTestHandler(t), h}, want: ErrorCodeESCMotorDuplicateHandler},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if _, errCode := NewMotorGroup(tt.handlers...); errCode != tt.want {
+					t.Fatalf("NewMotorGroup() = %v, want %v", errCode, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMotorGroupRampsInLockstep(t *testing.T) {
+	clock := newFakeClock()
+	group, recorders := newTestGroup(
+		t,
+		clock,
+		[]Option{WithPulseStep(100000)},
+		[]Option{WithPulseStep(250000), WithMaxSpeeds(0.5, 1)},
+	)
+	before := []int{recorders[0].count(), recorders[1].count()}
+	start := clock.Slept()
+
+	if i, errCode := group.SetSpeedAll(1, DirectionForward); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("SetSpeedAll() = %d, %v", i, errCode)
+	}
+
+	// The first motor has the most steps to travel, so both motors take its 5 steps, one per period
+	want := [][]uint32{
+		{1600000, 1700000, 1800000, 1900000, 2000000},
+		{1550000, 1600000, 1650000, 1700000, 1750000},
+	}
+	for i, recorder := range recorders {
+		got := recorder.since(before[i])
+		if len(got) != len(want[i]) {
+			t.Fatalf("motor %d wrote %v, want %v", i, got, want[i])
+		}
+		for j := range got {
+			if got[j] != want[i][j] {
+				t.Fatalf("motor %d wrote %v, want %v", i, got, want[i])
+			}
+		}
+	}
+	if slept, want := clock.Slept()-start, 4*20*time.Millisecond; slept != want {
+		t.Fatalf("ramp took %v, want %v", slept, want)
+	}
+}
+
+func TestMotorGroupReportsFailingMotor(t *testing.T) {
+	group, recorders := newTestGroup(
+		t,
+		newFakeClock(),
+		[]Option{WithPulseStep(100000)},
+		[]Option{WithPulseStep(100000)},
+		[]Option{WithPulseStep(100000)},
+	)
+	recorders[1].failAfter(3)
+
+	i, errCode := group.SetSpeedAll(1, DirectionForward)
+	if i != 1 || errCode != ErrorCodeESCMotorFailedToSetDuty {
+		t.Fatalf("SetSpeedAll() = %d, %v, want 1, ErrorCodeESCMotorFailedToSetDuty", i, errCode)
+	}
+
+	// The ramp stops at the failing step, the motors before the failing one wrote it and the ones after did not
+	handlers := group.GetHandlers()
+	for i, want := range []uint32{1800000, 1700000, 1700000} {
+		if got := handlers[i].GetPulse(); got != want {
+			t.Fatalf("motor %d pulse %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestMotorGroupDetoursThroughNeutral(t *testing.T) {
+	clock := newFakeClock()
+	group, recorders := newTestGroup(
+		t,
+		clock,
+		[]Option{WithDirectionDelays(0, 300*time.Millisecond)},
+		[]Option{WithDirectionDelays(0, 100*time.Millisecond)},
+	)
+	if i, errCode := group.SetSpeedAll(0.5, DirectionForward); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("SetSpeedAll() = %d, %v", i, errCode)
+	}
+	before := []int{recorders[0].count(), recorders[1].count()}
+	start := clock.Slept()
+
+	if i, errCode := group.SetSpeedAll(0.5, DirectionBackward); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("SetSpeedAll() = %d, %v", i, errCode)
+	}
+
+	// Both motors reach neutral, wait for the longest direction-change delay and then reverse together
+	for i, recorder := range recorders {
+		got := recorder.since(before[i])
+		if len(got) != 2 || got[0] != DefaultNeutralPulseWidth || got[1] != 1250000 {
+			t.Fatalf("motor %d wrote %v, want [%d 1250000]", i, got, DefaultNeutralPulseWidth)
+		}
+	}
+	if slept := clock.Slept() - start; slept != 300*time.Millisecond {
+		t.Fatalf("direction change waited %v, want 300ms", slept)
+	}
+}
+
+func TestMotorGroupAppliesBoostThreshold(t *testing.T) {
+	group, _ := newTestGroup(t, newFakeClock(), []Option{WithBoostThreshold(0.5)}, nil)
+
+	i, errCode := group.SetSpeedAll(1, DirectionForward)
+	if i != 0 || errCode != ErrorCodeESCMotorBoostNotEnabled {
+		t.Fatalf("SetSpeedAll() = %d, %v, want 0, ErrorCodeESCMotorBoostNotEnabled", i, errCode)
+	}
+	handlers := group.GetHandlers()
+	if handlers[0].GetSpeed() != 0.5 || handlers[1].GetSpeed() != 1 {
+		t.Fatalf("speeds %v and %v, want 0.5 and 1", handlers[0].GetSpeed(), handlers[1].GetSpeed())
+	}
+}
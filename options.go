@@ -0,0 +1,943 @@
+package tinygo_escmotor
+
+import (
+	"time"
+
+	"machine"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+	tinygologger "github.com/ralvarezdev/tinygo-logger"
+	tinygopwm "github.com/ralvarezdev/tinygo-pwm"
+)
+
+type (
+	// Option is a function to configure the handler created by NewHandler.
+	Option func(*config)
+
+	// config is the configuration of a handler built from its tuning profile and options
+	config struct {
+		Config
+		afterSetSpeedFunc     func(speed float64)
+		isMovementEnabled     func() bool
+		logger                tinygologger.Logger
+		isArmRequired         bool
+		protocol              Protocol
+		rampDuration          time.Duration
+		throttleCurve         ThrottleCurve
+		customThrottleCurve   func(input float64) float64
+		failsafeTimeout       time.Duration
+		onFailsafe            func()
+		isBrakeMode           bool
+		deadband              float64
+		is3DMode              bool
+		motorPoles            uint8
+		currentLimit          float64
+		currentSource         func() float64
+		onCurrentLimit        func()
+		thermalSource         func() float64
+		thermalStartTemp      float64
+		thermalMaxTemp        float64
+		softStartRate         float64
+		onPulseStep           func(pulse uint32)
+		logLevels             [logCategoryEnd]LogLevel
+		isDryRun              bool
+		writeDuty             func(pulse uint32) error
+		forwardPulseStep      *uint32
+		backwardPulseStep     *uint32
+		maxRampTime           time.Duration
+		isDirectInvert        bool
+		clock                 clock
+		isVerbosePulseLogging bool
+		isDeferredLogging     bool
+		stopPulse             StopPulse
+		minEffectiveSpeed     float64
+		isThrottleSideSwapped bool
+		inputSmoothing        float64
+		floatPrecision        uint8
+		boostThreshold        float64
+		idleKeepAlive         time.Duration
+		isDeferredInitialStop bool
+		isInitialStopSkipped  bool
+	}
+)
+
+const (
+	// DefaultFrequency is the default frequency for the PWM signal, the standard 50Hz servo frame rate
+	DefaultFrequency uint16 = 50
+
+	// DefaultMinPulseWidth is the default minimum pulse width in nanoseconds (1000µs)
+	DefaultMinPulseWidth uint32 = 1000000
+
+	// DefaultNeutralPulseWidth is the default neutral pulse width in nanoseconds (1500µs)
+	DefaultNeutralPulseWidth uint32 = 1500000
+
+	// DefaultMaxPulseWidth is the default maximum pulse width in nanoseconds (2000µs)
+	DefaultMaxPulseWidth uint32 = 2000000
+
+	// DefaultMaxForwardSpeed is the default maximum forward speed
+	DefaultMaxForwardSpeed = 1.0
+
+	// DefaultMaxBackwardSpeed is the default maximum backward speed
+	DefaultMaxBackwardSpeed = 1.0
+
+	// MinProtocolResolution is the minimum number of distinct duty cycle values the PWM must provide between the min and
+	// max pulse widths of the protocol chosen through WithProtocol
+	MinProtocolResolution uint32 = 100
+
+	// DefaultMotorPoles is the default number of magnetic poles of the motor, a single pole pair so the mechanical RPM
+	// equals the eRPM
+	DefaultMotorPoles uint8 = 2
+)
+
+// NewHandler creates a new instance of DefaultHandler configured by options. Unset options fall back to
+// DefaultFrequency, DefaultMinPulseWidth, DefaultNeutralPulseWidth, DefaultMaxPulseWidth, DefaultMaxForwardSpeed and
+// DefaultMaxBackwardSpeed, with no polarity inversion, no gradual ramp, no direction-change delays and no logger. The
+// motor is stopped initially through Stop, which blocks while ramping when a pulse step is set, unless
+// WithDeferredInitialStop or WithSkippedInitialStop is given.
+//
+// Parameters:
+//
+// pwm: The PWM interface to control the ESC motor
+// pin: The pin connected to the ESC motor
+// opts: The options to configure the handler
+//
+// Returns:
+//
+// An instance of DefaultHandler and an error if any occurred during initialization
+func NewHandler(
+	pwm tinygopwm.PWM,
+	pin machine.Pin,
+	opts ...Option,
+) (*DefaultHandler, tinygoerrors.ErrorCode) {
+	cfg := &config{
+		Config: Config{
+			Frequency:         DefaultFrequency,
+			MinPulseWidth:     DefaultMinPulseWidth,
+			NeutralPulseWidth: DefaultNeutralPulseWidth,
+			MaxPulseWidth:     DefaultMaxPulseWidth,
+			MaxForwardSpeed:   DefaultMaxForwardSpeed,
+			MaxBackwardSpeed:  DefaultMaxBackwardSpeed,
+		},
+		floatPrecision: Float64Precision,
+	}
+
+	// Apply the options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return newHandler(pwm, pin, cfg)
+}
+
+// WithConfig sets the whole tuning profile, overriding the options applied before it.
+//
+// Parameters:
+//
+// tuning: The tuning profile of the ESC motor
+//
+// Returns:
+//
+// The option to set the tuning profile
+func WithConfig(tuning Config) Option {
+	return func(cfg *config) {
+		cfg.Config = tuning
+	}
+}
+
+// WithFrequency sets the frequency for the PWM signal.
+//
+// Parameters:
+//
+// frequency: Frequency for the PWM signal
+//
+// Returns:
+//
+// The option to set the frequency
+func WithFrequency(frequency uint16) Option {
+	return func(cfg *config) {
+		cfg.Frequency = frequency
+	}
+}
+
+// WithPulseWidths sets the pulse widths for the ESC motor.
+//
+// Parameters:
+//
+// minPulseWidth: Minimum pulse width for the ESC motor
+// neutralPulseWidth: Neutral pulse width for the ESC motor
+// maxPulseWidth: Maximum pulse width for the ESC motor
+//
+// Returns:
+//
+// The option to set the pulse widths
+func WithPulseWidths(minPulseWidth, neutralPulseWidth, maxPulseWidth uint32) Option {
+	return func(cfg *config) {
+		cfg.MinPulseWidth = minPulseWidth
+		cfg.NeutralPulseWidth = neutralPulseWidth
+		cfg.MaxPulseWidth = maxPulseWidth
+	}
+}
+
+// WithMaxSpeeds sets the maximum speeds for each direction.
+//
+// Parameters:
+//
+// maxForwardSpeed: The maximum forward percentage speed value for the motor
+// maxBackwardSpeed: The maximum backward percentage speed value for the motor
+//
+// Returns:
+//
+// The option to set the maximum speeds
+func WithMaxSpeeds(maxForwardSpeed, maxBackwardSpeed float64) Option {
+	return func(cfg *config) {
+		cfg.MaxForwardSpeed = maxForwardSpeed
+		cfg.MaxBackwardSpeed = maxBackwardSpeed
+	}
+}
+
+// WithPolarityInverted sets whether the motor polarity is inverted.
+//
+// Parameters:
+//
+// isPolarityInverted: Whether the motor polarity is inverted
+//
+// Returns:
+//
+// The option to set the polarity inversion
+func WithPolarityInverted(isPolarityInverted bool) Option {
+	return func(cfg *config) {
+		cfg.IsPolarityInverted = isPolarityInverted
+	}
+}
+
+// WithSignalInverted sets whether the PWM signal is inverted.
+//
+// Parameters:
+//
+// isSignalInverted: Whether the PWM signal is inverted
+//
+// Returns:
+//
+// The option to set the signal inversion
+func WithSignalInverted(isSignalInverted bool) Option {
+	return func(cfg *config) {
+		cfg.IsSignalInverted = isSignalInverted
+	}
+}
+
+// WithUnidirectional sets whether the motor is unidirectional.
+//
+// Parameters:
+//
+// isUnidirectional: Whether the motor is unidirectional
+//
+// Returns:
+//
+// The option to set the unidirectional mode
+func WithUnidirectional(isUnidirectional bool) Option {
+	return func(cfg *config) {
+		cfg.IsUnidirectional = isUnidirectional
+	}
+}
+
+// WithPulseStep sets the step value for gradually changing the pulse width.
+//
+// Parameters:
+//
+// pulseStep: Step value for gradually changing the pulse width
+//
+// Returns:
+//
+// The option to set the pulse step
+func WithPulseStep(pulseStep uint32) Option {
+	return func(cfg *config) {
+		cfg.PulseStep = &pulseStep
+	}
+}
+
+// WithForwardPulseStep sets the step value for gradually changing the pulse width above neutral, falling back to the
+// pulse step when not set.
+//
+// Parameters:
+//
+// forwardPulseStep: Step value for gradually changing the pulse width above neutral
+//
+// Returns:
+//
+// The option to set the forward pulse step
+func WithForwardPulseStep(forwardPulseStep uint32) Option {
+	return func(cfg *config) {
+		cfg.forwardPulseStep = &forwardPulseStep
+	}
+}
+
+// WithBackwardPulseStep sets the step value for gradually changing the pulse width below neutral, falling back to the
+// pulse step when not set.
+//
+// Parameters:
+//
+// backwardPulseStep: Step value for gradually changing the pulse width below neutral
+//
+// Returns:
+//
+// The option to set the backward pulse step
+func WithBackwardPulseStep(backwardPulseStep uint32) Option {
+	return func(cfg *config) {
+		cfg.backwardPulseStep = &backwardPulseStep
+	}
+}
+
+// WithDirectionDelays sets the delays when changing direction.
+//
+// Parameters:
+//
+// backwardToForwardDelay: Delay when changing direction from backward to forward, zero or positive
+// forwardToBackwardDelay: Delay when changing direction from forward to backward, zero or positive
+//
+// Returns:
+//
+// The option to set the direction-change delays
+func WithDirectionDelays(backwardToForwardDelay, forwardToBackwardDelay time.Duration) Option {
+	return func(cfg *config) {
+		cfg.BackwardToForwardDelay = backwardToForwardDelay
+		cfg.ForwardToBackwardDelay = forwardToBackwardDelay
+	}
+}
+
+// WithPWMConfigureRetries sets how many times the PWM configuration is retried on failure.
+//
+// Parameters:
+//
+// retries: The number of retries after the first failed attempt
+// delay: The delay between attempts
+//
+// Returns:
+//
+// The option to set the PWM configuration retries
+func WithPWMConfigureRetries(retries uint8, delay time.Duration) Option {
+	return func(cfg *config) {
+		cfg.PWMConfigureRetries = retries
+		cfg.PWMConfigureRetryDelay = delay
+	}
+}
+
+// WithLogger sets the logger to log messages.
+//
+// Parameters:
+//
+// logger: The logger to log messages
+//
+// Returns:
+//
+// The option to set the logger
+func WithLogger(logger tinygologger.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithAfterSetSpeed sets the function to call after setting the speed.
+//
+// Parameters:
+//
+// afterSetSpeedFunc: Function to call after setting the speed
+//
+// Returns:
+//
+// The option to set the after set speed function
+func WithAfterSetSpeed(afterSetSpeedFunc func(speed float64)) Option {
+	return func(cfg *config) {
+		cfg.afterSetSpeedFunc = afterSetSpeedFunc
+	}
+}
+
+// WithMovementEnabled sets the function to check if movement is enabled.
+//
+// Parameters:
+//
+// isMovementEnabled: Function to check if movement is enabled
+//
+// Returns:
+//
+// The option to set the movement enabled function
+func WithMovementEnabled(isMovementEnabled func() bool) Option {
+	return func(cfg *config) {
+		cfg.isMovementEnabled = isMovementEnabled
+	}
+}
+
+// WithRequireArm sets whether the motor refuses to move until the arming sequence has completed.
+//
+// Parameters:
+//
+// isArmRequired: Whether SetSpeed refuses to move while the motor is not armed
+//
+// Returns:
+//
+// The option to require arming
+func WithRequireArm(isArmRequired bool) Option {
+	return func(cfg *config) {
+		cfg.isArmRequired = isArmRequired
+	}
+}
+
+// WithProtocol sets the frequency and the pulse widths of an ESC protocol timing preset, see the Protocol constants for
+// the exact ranges. Options applied after it override the preset values.
+//
+// Parameters:
+//
+// protocol: The ESC protocol timing preset
+//
+// Returns:
+//
+// The option to set the protocol
+func WithProtocol(protocol Protocol) Option {
+	return func(cfg *config) {
+		cfg.protocol = protocol
+		cfg.Frequency = protocol.Frequency()
+		cfg.MinPulseWidth, cfg.NeutralPulseWidth, cfg.MaxPulseWidth = protocol.PulseWidths()
+	}
+}
+
+// WithRampDuration sets the time every pulse width change takes, computing the step on each ramp from the distance to
+// travel. It takes precedence over the pulse step, and a duration shorter than the PWM period jumps to the target.
+//
+// Parameters:
+//
+// rampDuration: The time every ramp takes, zero falls back to the pulse step
+//
+// Returns:
+//
+// The option to set the ramp duration
+func WithRampDuration(rampDuration time.Duration) Option {
+	return func(cfg *config) {
+		cfg.rampDuration = rampDuration
+	}
+}
+
+// WithThrottleCurve sets the easing curve applied symmetrically to the forward and backward speeds before mapping them
+// onto the pulse width.
+//
+// Parameters:
+//
+// throttleCurve: The throttle curve, use WithCustomThrottleCurve for a custom one
+//
+// Returns:
+//
+// The option to set the throttle curve
+func WithThrottleCurve(throttleCurve ThrottleCurve) Option {
+	return func(cfg *config) {
+		cfg.throttleCurve = throttleCurve
+	}
+}
+
+// WithCustomThrottleCurve sets a custom easing curve applied symmetrically to the forward and backward speeds before
+// mapping them onto the pulse width. Its output is clamped to [0, 1].
+//
+// Parameters:
+//
+// curve: Function mapping a speed between 0 and 1 onto the curved speed
+//
+// Returns:
+//
+// The option to set the custom throttle curve
+func WithCustomThrottleCurve(curve func(input float64) float64) Option {
+	return func(cfg *config) {
+		cfg.throttleCurve = ThrottleCurveCustom
+		cfg.customThrottleCurve = curve
+	}
+}
+
+// WithFailsafe sets the timeout after which a background watchdog ramps the moving motor to neutral if no command or
+// feed arrived. The watchdog is stopped by Close.
+//
+// Parameters:
+//
+// timeout: The time without commands before the failsafe triggers, zero disables it
+//
+// Returns:
+//
+// The option to set the failsafe timeout
+func WithFailsafe(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.failsafeTimeout = timeout
+	}
+}
+
+// WithOnFailsafe sets the function called from the watchdog goroutine when the failsafe triggers.
+//
+// Parameters:
+//
+// onFailsafe: Function to call when the failsafe triggers
+//
+// Returns:
+//
+// The option to set the failsafe callback
+func WithOnFailsafe(onFailsafe func()) Option {
+	return func(cfg *config) {
+		cfg.onFailsafe = onFailsafe
+	}
+}
+
+// WithBrakeMode sets whether the ESC brakes when commanded backward while moving forward, as most car ESCs do, instead
+// of reversing right away.
+//
+// Parameters:
+//
+// isBrakeMode: Whether the backward commands brake the forward motion
+//
+// Returns:
+//
+// The option to set the brake mode
+func WithBrakeMode(isBrakeMode bool) Option {
+	return func(cfg *config) {
+		cfg.isBrakeMode = isBrakeMode
+	}
+}
+
+// WithDeadband sets the deadband around zero speed, any commanded speed below it is treated as a stop so the pulse
+// width does not hover just off neutral.
+//
+// Parameters:
+//
+// deadband: The speed below which the motor is stopped, between 0 and 1
+//
+// Returns:
+//
+// The option to set the deadband
+func WithDeadband(deadband float64) Option {
+	return func(cfg *config) {
+		cfg.deadband = deadband
+	}
+}
+
+// WithMode3D sets whether the ESC runs in 3D mode, where forward and backward are symmetric around a centered neutral
+// and the ESC reverses instantly, so the ramps pass straight through neutral without the direction-change delays.
+//
+// Parameters:
+//
+// is3DMode: Whether the ESC runs in 3D mode
+//
+// Returns:
+//
+// The option to set the 3D mode
+func WithMode3D(is3DMode bool) Option {
+	return func(cfg *config) {
+		cfg.is3DMode = is3DMode
+	}
+}
+
+// WithMotorPoles sets the number of magnetic poles of the motor, used to convert the eRPM reported by the ESC telemetry
+// into the mechanical RPM.
+//
+// Parameters:
+//
+// motorPoles: The number of magnetic poles of the motor, must be even, zero falls back to DefaultMotorPoles
+//
+// Returns:
+//
+// The option to set the number of motor poles
+func WithMotorPoles(motorPoles uint8) Option {
+	return func(cfg *config) {
+		cfg.motorPoles = motorPoles
+	}
+}
+
+// WithCurrentLimit sets the current limit protection, the motor is pulled back toward neutral while the sensed current
+// exceeds the limit and released once it drops below CurrentLimitHysteresis times the limit. The current is checked on
+// every speed command and periodically from a background goroutine stopped by Close.
+//
+// Parameters:
+//
+// maxAmps: The current limit in amperes
+// source: Function returning the sensed current in amperes, nil disables the protection
+//
+// Returns:
+//
+// The option to set the current limit
+func WithCurrentLimit(maxAmps float64, source func() float64) Option {
+	return func(cfg *config) {
+		cfg.currentLimit = maxAmps
+		cfg.currentSource = source
+	}
+}
+
+// WithOnCurrentLimit sets the function called when the sensed current exceeds the current limit.
+//
+// Parameters:
+//
+// onCurrentLimit: Function to call when the current limit is exceeded
+//
+// Returns:
+//
+// The option to set the current limit callback
+func WithOnCurrentLimit(onCurrentLimit func()) Option {
+	return func(cfg *config) {
+		cfg.onCurrentLimit = onCurrentLimit
+	}
+}
+
+// WithThermalDerate sets the thermal derating, the speed ceiling scales linearly from the full speed at the start
+// temperature down to a stop at the max temperature, and returns to normal once the temperature falls below the start
+// temperature.
+//
+// Parameters:
+//
+// source: Function returning the sensed temperature, nil disables the derating
+// startTemp: The temperature the derating starts at
+// maxTemp: The temperature the speed ceiling reaches zero at, must be higher than the start temperature
+//
+// Returns:
+//
+// The option to set the thermal derating
+func WithThermalDerate(source func() float64, startTemp, maxTemp float64) Option {
+	return func(cfg *config) {
+		cfg.thermalSource = source
+		cfg.thermalStartTemp = startTemp
+		cfg.thermalMaxTemp = maxTemp
+	}
+}
+
+// WithSoftStart sets the soft start, limiting how fast the speed increases when the motor launches from a stop. Once
+// the motor is moving, the regular ramp applies again.
+//
+// Parameters:
+//
+// rampUpRate: The maximum speed increase per second when launching from a stop, zero disables the soft start
+//
+// Returns:
+//
+// The option to set the soft start
+func WithSoftStart(rampUpRate float64) Option {
+	return func(cfg *config) {
+		cfg.softStartRate = rampUpRate
+	}
+}
+
+// WithOnPulseStep sets the function called in ramp order with every pulse width written by a ramp, the intermediate
+// steps as well as the final one.
+//
+// Parameters:
+//
+// onPulseStep: Function to call with every pulse width of a ramp
+//
+// Returns:
+//
+// The option to set the pulse step callback
+func WithOnPulseStep(onPulseStep func(pulse uint32)) Option {
+	return func(cfg *config) {
+		cfg.onPulseStep = onPulseStep
+	}
+}
+
+// WithLogLevel sets the logger method the log messages of a category are routed to, every category defaults to
+// LogLevelDebug. LogLevelNone suppresses the category, e.g. the per-step pulse width messages of the ramps.
+//
+// Parameters:
+//
+// category: The category of the log messages
+// level: The logger method the log messages are routed to
+//
+// Returns:
+//
+// The option to set the log level of the category
+func WithLogLevel(category LogCategory, level LogLevel) Option {
+	return func(cfg *config) {
+		if category < logCategoryEnd {
+			cfg.logLevels[category] = level
+		}
+	}
+}
+
+// WithDryRun sets whether the handler runs a dry run, recording the pulse widths it would write into the pulse history
+// instead of configuring and driving the PWM, which can then be nil. The timing and the validation run as usual.
+//
+// Parameters:
+//
+// isDryRun: Whether the handler runs a dry run
+//
+// Returns:
+//
+// The option to set the dry run
+func WithDryRun(isDryRun bool) Option {
+	return func(cfg *config) {
+		cfg.isDryRun = isDryRun
+	}
+}
+
+// NewSimHandler creates a new instance of DefaultHandler running a dry run without a PWM, see NewHandler and
+// WithDryRun.
+//
+// Parameters:
+//
+// opts: The options to configure the handler
+//
+// Returns:
+//
+// An instance of DefaultHandler and an error if any occurred during initialization
+func NewSimHandler(opts ...Option) (*DefaultHandler, tinygoerrors.ErrorCode) {
+	return NewHandler(nil, 0, append(append([]Option(nil), opts...), WithDryRun(true))...)
+}
+
+// WithDutyWriter replaces the function writing every pulse width to the PWM, e.g. to record the pulse widths in host
+// tests. The writer receives the pulse width before the signal inversion, and a returned error aborts the write with
+// ErrorCodeESCMotorFailedToSetDuty.
+//
+// Parameters:
+//
+// writeDuty: Function writing the pulse width, nil falls back to driving the PWM
+//
+// Returns:
+//
+// The option to set the duty writer
+func WithDutyWriter(writeDuty func(pulse uint32) error) Option {
+	return func(cfg *config) {
+		cfg.writeDuty = writeDuty
+	}
+}
+
+// WithMaxRampTime sets the cap on the time a single ramp takes, enlarging the pulse width step of the ramps that would
+// take longer so they finish within it. It bounds the ramps only, the zero-cross dwell and the direction-change delays
+// still add to the time SetSpeed blocks.
+//
+// Parameters:
+//
+// maxRampTime: The maximum time of a ramp, zero disables the cap
+//
+// Returns:
+//
+// The option to set the maximum ramp time
+func WithMaxRampTime(maxRampTime time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxRampTime = maxRampTime
+	}
+}
+
+// WithDirectInvert sets whether the direction reversals sweep straight through neutral to the target in a single
+// continuous ramp, without stopping at neutral first nor waiting for the direction-change delays. It suits the ESCs
+// that reverse on the fly, the default stops at neutral first since most ESCs ignore a reversal without a stop.
+//
+// Parameters:
+//
+// isDirectInvert: Whether the direction reversals sweep straight through neutral
+//
+// Returns:
+//
+// The option to set the direct inversion
+func WithDirectInvert(isDirectInvert bool) Option {
+	return func(cfg *config) {
+		cfg.isDirectInvert = isDirectInvert
+	}
+}
+
+// WithVerbosePulseLogging sets whether every intermediate step of the ramps is logged. The default only logs the final
+// pulse width of every ramp, since logging every step floods slow serial links with hundreds of lines per speed change.
+//
+// Parameters:
+//
+// isVerbosePulseLogging: Whether the intermediate pulse width steps are logged
+//
+// Returns:
+//
+// The option to set the verbose pulse logging
+func WithVerbosePulseLogging(isVerbosePulseLogging bool) Option {
+	return func(cfg *config) {
+		cfg.isVerbosePulseLogging = isVerbosePulseLogging
+	}
+}
+
+// WithDeferredLogging sets whether the pulse width logs of a ramp are deferred until the ramp completes, emitting a
+// single summary with the start and target pulse widths and the number of steps instead of logging while stepping, so
+// the logging I/O on slow links does not stretch the steps beyond the period.
+//
+// Parameters:
+//
+// isDeferredLogging: Whether the pulse width logs of a ramp are deferred
+//
+// Returns:
+//
+// The option to set the deferred logging
+func WithDeferredLogging(isDeferredLogging bool) Option {
+	return func(cfg *config) {
+		cfg.isDeferredLogging = isDeferredLogging
+	}
+}
+
+// WithStopPulse sets the pulse width driven when the motor is stopped, by Stop, EmergencyStop or a stop command. Most
+// car, boat and 3D ESCs treat neutral as stopped and want StopPulseNeutral, the default. Some ESCs, e.g. the aircraft
+// ESCs whose neutral is configured as idle throttle, only treat the min pulse width as off and want StopPulseMin. The
+// min pulse width is rejected in 3D mode, where it drives full reverse.
+//
+// Parameters:
+//
+// stopPulse: The pulse width driven when the motor is stopped
+//
+// Returns:
+//
+// The option to set the stop pulse
+func WithStopPulse(stopPulse StopPulse) Option {
+	return func(cfg *config) {
+		cfg.stopPulse = stopPulse
+	}
+}
+
+// WithMinEffectiveSpeed sets the lowest speed the motor actually turns at, any nonzero forward or backward command
+// below it is bumped up to it, so the brushless ESCs that stall at the low pulses never buzz without turning. A zero
+// speed still stops the motor.
+//
+// Parameters:
+//
+// minEffectiveSpeed: The min effective speed, between 0 and 1, zero disables it
+//
+// Returns:
+//
+// The option to set the min effective speed
+func WithMinEffectiveSpeed(minEffectiveSpeed float64) Option {
+	return func(cfg *config) {
+		cfg.minEffectiveSpeed = minEffectiveSpeed
+	}
+}
+
+// WithSwappedThrottleSides sets whether the half of the pulse widths above neutral maps to the backward direction and
+// the half below neutral to the forward direction. Unlike the polarity inversion, which swaps the commanded directions,
+// it only swaps the halves of the mapping, the direction bookkeeping, the max speeds, the per-direction pulse steps and
+// the direction-change delays keep following the handler directions, while the start offsets follow the halves. Both
+// combine as follows, for a forward command:
+//
+//	polarity inverted | sides swapped | pulse width
+//	false             | false         | above neutral
+//	true              | false         | below neutral
+//	false             | true          | below neutral
+//	true              | true          | above neutral
+//
+// Parameters:
+//
+// isThrottleSideSwapped: Whether the halves of the pulse widths are swapped
+//
+// Returns:
+//
+// The option to set the swapped throttle sides
+func WithSwappedThrottleSides(isThrottleSideSwapped bool) Option {
+	return func(cfg *config) {
+		cfg.isThrottleSideSwapped = isThrottleSideSwapped
+	}
+}
+
+// WithInputSmoothing sets the factor of the exponential moving average applied to the incoming speeds before mapping
+// them onto the pulse width, filtering out the jitter of noisy inputs. A factor near 1 is responsive while a factor
+// near 0 smooths heavily. The stop commands reset the filter so the motor does not remember a stale throttle.
+//
+// Parameters:
+//
+// inputSmoothing: The smoothing factor, between 0 and 1, zero disables it
+//
+// Returns:
+//
+// The option to set the input smoothing
+func WithInputSmoothing(inputSmoothing float64) Option {
+	return func(cfg *config) {
+		cfg.inputSmoothing = inputSmoothing
+	}
+}
+
+// WithFloatPrecision sets the number of decimals of the float values in the log messages, e.g. fewer on a slow serial
+// link. The default is Float64Precision.
+//
+// Parameters:
+//
+// floatPrecision: The number of decimals
+//
+// Returns:
+//
+// The option to set the float precision
+func WithFloatPrecision(floatPrecision uint8) Option {
+	return func(cfg *config) {
+		cfg.floatPrecision = floatPrecision
+	}
+}
+
+// WithBoostThreshold sets the forward speed above which the boost region starts, the forward commands above it are
+// clamped to it and return ErrorCodeESCMotorBoostNotEnabled until EnableBoost is called, preventing accidental
+// full-throttle commands.
+//
+// Parameters:
+//
+// boostThreshold: The forward speed where the boost region starts, between 0 and 1, zero disables it
+//
+// Returns:
+//
+// The option to set the boost threshold
+func WithBoostThreshold(boostThreshold float64) Option {
+	return func(cfg *config) {
+		cfg.boostThreshold = boostThreshold
+	}
+}
+
+// WithIdleKeepAlive sets the interval a background goroutine re-writes the stop pulse width at while the motor is
+// stopped, for the ESCs that disarm when they receive no signal for a while even at neutral. It pauses while the motor
+// is driven or coasting and resumes once stopped again. The goroutine is stopped by Close.
+//
+// Parameters:
+//
+// interval: The interval between two re-writes, zero disables it
+//
+// Returns:
+//
+// The option to set the idle keep-alive
+func WithIdleKeepAlive(interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.idleKeepAlive = interval
+	}
+}
+
+// WithDeferredInitialStop sets whether the constructor stops the motor with a single direct write of the stop pulse
+// width instead of calling Stop, which ramps gradually when a pulse step is set and blocks the constructor while it
+// sleeps between the steps. The later commands ramp as usual.
+//
+// Parameters:
+//
+// isDeferredInitialStop: Whether the initial stop is a single direct write
+//
+// Returns:
+//
+// The option to set the deferred initial stop
+func WithDeferredInitialStop(isDeferredInitialStop bool) Option {
+	return func(cfg *config) {
+		cfg.isDeferredInitialStop = isDeferredInitialStop
+	}
+}
+
+// WithSkippedInitialStop sets whether the constructor skips the initial stop entirely, leaving the PWM output
+// untouched until the first command, e.g. for the callers that arm the ESC explicitly. The pulse width is unknown until
+// then, so IsStopped returns false and the first command always writes its pulse width, even a stop. It takes
+// precedence over WithDeferredInitialStop.
+//
+// Parameters:
+//
+// isInitialStopSkipped: Whether the initial stop is skipped
+//
+// Returns:
+//
+// The option to skip the initial stop
+func WithSkippedInitialStop(isInitialStopSkipped bool) Option {
+	return func(cfg *config) {
+		cfg.isInitialStopSkipped = isInitialStopSkipped
+	}
+}
+
+// withClock replaces the source of time of the handler, e.g. with a fake clock so the timing logic runs instantly in
+// the tests.
+//
+// Parameters:
+//
+// clock: The source of time, nil falls back to the wall clock
+//
+// Returns:
+//
+// The option to set the clock
+func withClock(clock clock) Option {
+	return func(cfg *config) {
+		cfg.clock = clock
+	}
+}
@@ -0,0 +1,287 @@
+package tinygo_escmotor
+
+import (
+	"sync"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// RateLimitedHandler is the Handler decorator that forwards at most one speed command per interval to the wrapped
+	// handler, coalescing the commands arriving faster into the latest one, which is forwarded once the interval
+	// elapses. Stop is never rate limited, it discards the commands not forwarded yet and reaches the wrapped handler
+	// after the forwarded command still running, if any, so no command issued before it moves the motor afterwards.
+	RateLimitedHandler struct {
+		handler          Handler
+		interval         time.Duration
+		clock            clock
+		mutex            sync.Mutex
+		forwardMutex     sync.Mutex
+		lastSent         time.Time
+		pending          func() tinygoerrors.ErrorCode
+		pendingStopCount uint32
+		stopTimer        func() bool
+		droppedCount     uint32
+		lastErrorCode    tinygoerrors.ErrorCode
+		stopCount        uint32
+	}
+)
+
+// NewRateLimitedHandler creates a new instance of RateLimitedHandler
+//
+// Parameters:
+//
+// handler: The handler to wrap
+// interval: The minimum time between two forwarded speed commands, e.g. the period of the ESC frame rate
+//
+// Returns:
+//
+// An instance of RateLimitedHandler and an error if the handler is nil or the interval is not positive
+func NewRateLimitedHandler(handler Handler, interval time.Duration) (*RateLimitedHandler, tinygoerrors.ErrorCode) {
+	// Check if the handler is nil
+	if handler == nil {
+		return nil, ErrorCodeESCMotorNilHandler
+	}
+
+	// Check if the interval is valid
+	if interval <= 0 {
+		return nil, ErrorCodeESCMotorInvalidRateLimitInterval
+	}
+
+	// Share the clock of the handler if it has one
+	var source clock = realClock{}
+	if provider, ok := handler.(clockSource); ok {
+		source = provider.getClock()
+	}
+
+	return &RateLimitedHandler{
+		handler:  handler,
+		interval: interval,
+		clock:    source,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// send forwards a speed command, or keeps it as the pending one if the interval since the last forwarded command has
+// not elapsed yet
+//
+// Parameters:
+//
+// cmd: The function forwarding the command to the wrapped handler
+//
+// Returns:
+//
+// The error returned by the wrapped handler, nil if the command is pending
+func (r *RateLimitedHandler) send(cmd func() tinygoerrors.ErrorCode) tinygoerrors.ErrorCode {
+	r.mutex.Lock()
+
+	// Check if a pending command is superseded
+	if r.pending != nil {
+		r.droppedCount++
+		r.pending = nil
+	}
+
+	// Check if the command arrives too soon, in which case it is forwarded once the interval elapses, unless a stop
+	// arrives first
+	if !r.lastSent.IsZero() {
+		if elapsed := r.clock.Now().Sub(r.lastSent); elapsed < r.interval {
+			r.pending = cmd
+			r.pendingStopCount = r.stopCount
+			if r.stopTimer == nil {
+				r.stopTimer = r.clock.AfterFunc(r.interval-elapsed, r.flush)
+			}
+			r.mutex.Unlock()
+			return tinygoerrors.ErrorCodeNil
+		}
+	}
+	return r.forward(cmd, r.stopCount)
+}
+
+// forward forwards a command to the wrapped handler, the mutex must be held and is released while the command runs,
+// since the wrapped handler may block for a whole ramp
+//
+// Parameters:
+//
+// cmd: The function forwarding the command to the wrapped handler
+// stopCount: The number of stops forwarded when the command was issued
+//
+// Returns:
+//
+// The error returned by the wrapped handler, nil if the command was discarded by a stop
+func (r *RateLimitedHandler) forward(cmd func() tinygoerrors.ErrorCode, stopCount uint32) tinygoerrors.ErrorCode {
+	r.lastSent = r.clock.Now()
+	r.mutex.Unlock()
+
+	// Check if a stop arrived since the command was issued, serialized with the stops reaching the wrapped handler so
+	// the command cannot reach it after a later stop
+	r.forwardMutex.Lock()
+	defer r.forwardMutex.Unlock()
+	r.mutex.Lock()
+	if r.stopCount != stopCount {
+		r.droppedCount++
+		r.mutex.Unlock()
+		return tinygoerrors.ErrorCodeNil
+	}
+	r.mutex.Unlock()
+
+	errCode := cmd()
+
+	// Record the error, unless a stop was forwarded meanwhile, whose error takes precedence
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.stopCount == stopCount {
+		r.lastErrorCode = errCode
+	}
+	return errCode
+}
+
+// flush forwards the pending command once the interval elapses
+func (r *RateLimitedHandler) flush() {
+	r.mutex.Lock()
+	r.stopTimer = nil
+	if r.pending == nil {
+		r.mutex.Unlock()
+		return
+	}
+	cmd := r.pending
+	r.pending = nil
+	r.forward(cmd, r.pendingStopCount)
+}
+
+// cancelPending discards the pending command, the mutex must be held
+func (r *RateLimitedHandler) cancelPending() {
+	if r.stopTimer != nil {
+		r.stopTimer()
+		r.stopTimer = nil
+	}
+	if r.pending != nil {
+		r.droppedCount++
+		r.pending = nil
+	}
+}
+
+// GetSpeed returns the current speed of the wrapped handler.
+//
+// Returns:
+//
+// The current speed
+func (r *RateLimitedHandler) GetSpeed() float64 {
+	return r.handler.GetSpeed()
+}
+
+// Stop stops the wrapped handler without rate limiting, discarding the pending command and the commands issued before
+// it that have not reached the wrapped handler yet. It reaches the wrapped handler once the forwarded command still
+// running, if any, returns, so it is always the last command the wrapped handler receives.
+//
+// Returns:
+//
+// An error if the speed could not be set to 0, otherwise nil.
+func (r *RateLimitedHandler) Stop() tinygoerrors.ErrorCode {
+	r.mutex.Lock()
+	r.cancelPending()
+	r.stopCount++
+	r.lastSent = r.clock.Now()
+	r.mutex.Unlock()
+
+	// Wait for the forwarded command still running, the commands not started yet are discarded
+	r.forwardMutex.Lock()
+	errCode := r.handler.Stop()
+	r.forwardMutex.Unlock()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lastErrorCode = errCode
+	return errCode
+}
+
+// SetSpeed sets the speed of the wrapped handler, subject to the rate limit.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, nil if it was set or is pending.
+func (r *RateLimitedHandler) SetSpeed(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	return r.send(
+		func() tinygoerrors.ErrorCode {
+			return r.handler.SetSpeed(speed, direction)
+		},
+	)
+}
+
+// SetSpeedForward sets the forward speed of the wrapped handler, subject to the rate limit.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+//
+// Returns:
+//
+// An error if the speed could not be set, nil if it was set or is pending.
+func (r *RateLimitedHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	return r.send(
+		func() tinygoerrors.ErrorCode {
+			return r.handler.SetSpeedForward(speed)
+		},
+	)
+}
+
+// SetSpeedBackward sets the backward speed of the wrapped handler, subject to the rate limit.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+//
+// Returns:
+//
+// An error if the speed could not be set, nil if it was set or is pending.
+func (r *RateLimitedHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
+	return r.send(
+		func() tinygoerrors.ErrorCode {
+			return r.handler.SetSpeedBackward(speed)
+		},
+	)
+}
+
+// IsStopped returns whether the wrapped handler is stopped.
+//
+// Returns:
+//
+// True if the motor is stopped, otherwise false
+func (r *RateLimitedHandler) IsStopped() bool {
+	return r.handler.IsStopped()
+}
+
+// IsMoving returns whether the wrapped handler is moving.
+//
+// Returns:
+//
+// True if the motor is moving, otherwise false
+func (r *RateLimitedHandler) IsMoving() bool {
+	return r.handler.IsMoving()
+}
+
+// GetDroppedCount returns how many commands were coalesced, superseded by a later command before being forwarded.
+//
+// Returns:
+//
+// The number of dropped commands
+func (r *RateLimitedHandler) GetDroppedCount() uint32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.droppedCount
+}
+
+// GetLastErrorCode returns the error of the last forwarded command, including the pending commands forwarded once the
+// interval elapsed, whose error cannot be returned to the caller.
+//
+// Returns:
+//
+// The error of the last forwarded command, nil if it succeeded
+func (r *RateLimitedHandler) GetLastErrorCode() tinygoerrors.ErrorCode {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.lastErrorCode
+}
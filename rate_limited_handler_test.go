@@ -0,0 +1,159 @@
+package tinygo_escmotor
+
+import (
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// blockingHandler is the mock Handler whose forward commands block until released, like a long ramp
+	blockingHandler struct {
+		mockHandler
+		started chan struct{}
+		release chan struct{}
+	}
+)
+
+func (b *blockingHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	b.started <- struct{}{}
+	<-b.release
+	return b.mockHandler.SetSpeedForward(speed)
+}
+
+// newTestRateLimitedHandler creates a rate limited handler wrapping a handler on a fake clock
+//
+// Parameters:
+//
+// t: The test
+// handler: The handler to wrap
+// interval: The minimum time between two forwarded speed commands
+//
+// Returns:
+//
+// The rate limited handler
+func newTestRateLimitedHandler(t *testing.T, handler Handler, interval time.Duration) *RateLimitedHandler {
+	t.Helper()
+	r, errCode := NewRateLimitedHandler(handler, interval)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewRateLimitedHandler() = %v", errCode)
+	}
+	return r
+}
+
+func TestRateLimitedHandlerCoalesces(t *testing.T) {
+	clock := newFakeClock()
+	handler := &mockHandler{clock: clock}
+	r := newTestRateLimitedHandler(t, handler, 200*time.Millisecond)
+
+	// The first command is forwarded at once, the next ones within the interval are coalesced into the last one
+	for _, speed := range []float64{0.1, 0.2, 0.3, 0.4} {
+		if errCode := r.SetSpeedForward(speed); errCode != tinygoerrors.ErrorCodeNil {
+			t.Fatalf("SetSpeedForward(%v) = %v", speed, errCode)
+		}
+	}
+	if got := handler.GetSpeed(); got != 0.1 {
+		t.Fatalf("speed %v right after the commands, want 0.1", got)
+	}
+	if got := r.GetDroppedCount(); got != 2 {
+		t.Fatalf("GetDroppedCount() = %d, want 2", got)
+	}
+
+	// The latest command is not forwarded before the interval elapses
+	clock.Advance(199 * time.Millisecond)
+	if got := handler.GetSpeed(); got != 0.1 {
+		t.Fatalf("speed %v before the interval elapsed, want 0.1", got)
+	}
+
+	// The latest command is forwarded once the interval elapses
+	clock.Advance(time.Millisecond)
+	if got := handler.GetSpeed(); got != 0.4 {
+		t.Fatalf("speed %v once the interval elapsed, want 0.4", got)
+	}
+	handler.mutex.Lock()
+	calls := handler.calls
+	handler.mutex.Unlock()
+	if calls != 2 {
+		t.Fatalf("wrapped handler received %d commands, want 2", calls)
+	}
+	if got := r.GetLastErrorCode(); got != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("GetLastErrorCode() = %v", got)
+	}
+}
+
+func TestRateLimitedHandlerStopDiscardsPending(t *testing.T) {
+	clock := newFakeClock()
+	handler := &mockHandler{clock: clock}
+	r := newTestRateLimitedHandler(t, handler, 100*time.Millisecond)
+
+	_ = r.SetSpeedForward(0.5)
+	_ = r.SetSpeedForward(0.7)
+	if errCode := r.Stop(); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("Stop() = %v", errCode)
+	}
+	if got := r.GetDroppedCount(); got != 1 {
+		t.Fatalf("GetDroppedCount() = %d, want 1", got)
+	}
+
+	// The discarded command never reaches the wrapped handler
+	clock.Advance(time.Second)
+	if got := handler.GetSpeed(); got != 0 {
+		t.Fatalf("speed %v after Stop, want 0", got)
+	}
+}
+
+func TestRateLimitedHandlerStopReachesHandlerLast(t *testing.T) {
+	handler := &blockingHandler{
+		mockHandler: mockHandler{clock: newFakeClock()},
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	r := newTestRateLimitedHandler(t, handler, time.Millisecond)
+
+	// Start a command that blocks in the wrapped handler
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = r.SetSpeedForward(0.5)
+	}()
+	<-handler.started
+
+	// Stop waits for the running command instead of racing it to the wrapped handler
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = r.Stop()
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop() returned before the running command")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(handler.release)
+	<-done
+	<-stopped
+	if got := handler.GetSpeed(); got != 0 {
+		t.Fatalf("speed %v after Stop, want 0", got)
+	}
+}
+
+func TestRateLimitedHandlerDropsCommandsIssuedBeforeStop(t *testing.T) {
+	clock := newFakeClock()
+	handler := &mockHandler{clock: clock}
+	r := newTestRateLimitedHandler(t, handler, 100*time.Millisecond)
+
+	// A command issued before a stop is dropped even if it is flushed after the stop was counted
+	mustSucceed(t, r.SetSpeedForward(0.5))
+	mustSucceed(t, r.SetSpeedForward(0.7))
+	r.mutex.Lock()
+	r.stopCount++
+	r.mutex.Unlock()
+	clock.Advance(100 * time.Millisecond)
+	if got := handler.GetSpeed(); got != 0.5 {
+		t.Fatalf("speed %v, want the command issued before the stop dropped", got)
+	}
+	if got := r.GetDroppedCount(); got != 1 {
+		t.Fatalf("GetDroppedCount() = %d, want 1", got)
+	}
+}
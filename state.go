@@ -0,0 +1,158 @@
+package tinygo_escmotor
+
+import (
+	"encoding/binary"
+	"math"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+const (
+	// StateVersion is the version of the binary layout used to serialize the handler state
+	StateVersion uint8 = 1
+
+	// StateSize is the size in bytes of a serialized handler state
+	StateSize = 19
+)
+
+const (
+	// stateFlagPolarityInverted is the flag set when the motor polarity is inverted
+	stateFlagPolarityInverted uint8 = 1 << iota
+
+	// stateFlagSignalInverted is the flag set when the PWM signal is inverted
+	stateFlagSignalInverted
+
+	// stateFlagArmed is the flag set when the motor is armed
+	stateFlagArmed
+
+	// stateFlagNeutralLocked is the flag set when the motor is locked at neutral
+	stateFlagNeutralLocked
+
+	// stateFlagManualOverride is the flag set when the pulse width was set outside of the speed commands
+	stateFlagManualOverride
+
+	// stateFlagBoostEnabled is the flag set when the speeds above the boost threshold are enabled
+	stateFlagBoostEnabled
+)
+
+// MarshalState serializes the runtime state of the handler, e.g. to log it or to restore it after a soft reset, using
+// a fixed little-endian binary layout. The tuning profile is serialized separately by MarshalConfig.
+//
+// Returns:
+//
+// The speed, direction, pulse width, neutral trim and flags serialized with the fixed binary layout of StateSize bytes
+func (h *DefaultHandler) MarshalState() []byte {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+
+	data := make([]byte, StateSize)
+	data[0] = StateVersion
+	binary.LittleEndian.PutUint64(data[1:9], math.Float64bits(h.speed))
+	data[9] = uint8(h.direction)
+	binary.LittleEndian.PutUint32(data[10:14], h.pulse)
+	binary.LittleEndian.PutUint32(data[14:18], uint32(h.neutralTrim))
+
+	// Set the flags
+	var flags uint8
+	if h.isPolarityInverted {
+		flags |= stateFlagPolarityInverted
+	}
+	if h.isSignalInverted {
+		flags |= stateFlagSignalInverted
+	}
+	if h.isArmed {
+		flags |= stateFlagArmed
+	}
+	if h.isNeutralLocked {
+		flags |= stateFlagNeutralLocked
+	}
+	if h.isManualOverride {
+		flags |= stateFlagManualOverride
+	}
+	if h.isBoostEnabled {
+		flags |= stateFlagBoostEnabled
+	}
+	data[18] = flags
+	return data
+}
+
+// RestoreState restores the runtime state serialized by MarshalState, sending the saved speed and direction through
+// the same path as SetSpeed, so the arm requirement, the neutral lock, the movement enable function and the boost
+// threshold all apply. The armed flag is never restored, Arm must be called again before a motor requiring it moves,
+// and a state saved under a manual override, e.g. after SetRawPulse, is restored as a stop since its pulse width does
+// not follow a speed command.
+//
+// Parameters:
+//
+// data: The serialized handler state
+//
+// Returns:
+//
+// An error if the handler has been closed, the data has an invalid size, version, direction or speed, the pulse width
+// is out of range, or the speed could not be set, otherwise nil
+func (h *DefaultHandler) RestoreState(data []byte) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpRestoreState)
+	}
+
+	// Check the size and the version of the layout
+	if len(data) != StateSize || data[0] != StateVersion {
+		return h.reportError(ErrorCodeESCMotorInvalidStateData, OpRestoreState)
+	}
+
+	// Check if the speed and the direction are valid
+	speed := math.Float64frombits(binary.LittleEndian.Uint64(data[1:9]))
+	direction := Direction(data[9])
+	if math.IsNaN(speed) || speed < -1 || speed > 1 || !direction.IsValid() {
+		return h.reportError(ErrorCodeESCMotorInvalidStateData, OpRestoreState)
+	}
+
+	// Check if the polarity is inverted on a unidirectional motor, in which case it cannot go backward
+	flags := data[18]
+	if flags&stateFlagPolarityInverted != 0 && h.isUnidirectional {
+		return h.reportError(ErrorCodeESCMotorBackwardNotSupported, OpRestoreState)
+	}
+
+	// Check if the pulse width is within the configured range, a saved pulse width outside of it comes from another
+	// tuning profile or a corrupted state
+	pulse := binary.LittleEndian.Uint32(data[10:14])
+	if pulse < h.minPulseWidth || pulse > h.maxPulseWidth {
+		return h.reportError(ErrorCodeESCMotorRawPulseOutOfRange, OpRestoreState)
+	}
+
+	// Restore the trim and the flags, except the armed flag
+	isSignalInverted := flags&stateFlagSignalInverted != 0
+	h.stateMutex.Lock()
+	isSignalChanged := h.isSignalInverted != isSignalInverted
+	h.setNeutralTrim(int32(binary.LittleEndian.Uint32(data[14:18])))
+	h.isPolarityInverted = flags&stateFlagPolarityInverted != 0
+	h.isSignalInverted = isSignalInverted
+	h.isNeutralLocked = flags&stateFlagNeutralLocked != 0
+	h.isBoostEnabled = flags&stateFlagBoostEnabled != 0
+	h.stateMutex.Unlock()
+
+	// Re-drive the current pulse width with the restored signal inversion
+	if isSignalChanged {
+		if errCode := h.rewritePulseWidth(); errCode != tinygoerrors.ErrorCodeNil {
+			return h.reportError(errCode, OpRestoreState)
+		}
+	}
+
+	// Get the commanded speed and direction from the physical ones, a manual override or a brake is restored as a stop
+	commandedSpeed := 0.0
+	commandedDirection := DirectionStop
+	if flags&stateFlagManualOverride == 0 && (direction == DirectionForward || direction == DirectionBackward) {
+		commandedSpeed = math.Abs(speed)
+		commandedDirection = direction
+		if h.isPolarityInverted {
+			commandedDirection = direction.InvertedDirection()
+		}
+	}
+
+	// Set the speed like SetSpeed does
+	return h.reportError(h.setSpeed(commandedSpeed, commandedDirection), OpRestoreState)
+}
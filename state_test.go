@@ -0,0 +1,92 @@
+package tinygo_escmotor
+
+import (
+	"encoding/binary"
+	"testing"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+func TestRestoreStateRequiresArmingAgain(t *testing.T) {
+	saved := newTestHandler(t, WithRequireArm(true))
+	if errCode := saved.Arm(0); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("Arm() = %v", errCode)
+	}
+	if errCode := saved.SetSpeedForward(0.5); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("SetSpeedForward() = %v", errCode)
+	}
+	data := saved.MarshalState()
+
+	// The armed flag is not restored, so the motor stays stopped
+	h := newTestHandler(t, WithRequireArm(true))
+	if errCode := h.RestoreState(data); errCode != ErrorCodeESCMotorNotArmed {
+		t.Fatalf("RestoreState() = %v, want ErrorCodeESCMotorNotArmed", errCode)
+	}
+	if h.IsArmed() || !h.IsStopped() {
+		t.Fatal("RestoreState() armed or moved the motor")
+	}
+
+	// Once armed again, the saved speed is set through the usual path
+	if errCode := h.Arm(0); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("Arm() = %v", errCode)
+	}
+	if errCode := h.RestoreState(data); errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("RestoreState() = %v", errCode)
+	}
+	if h.GetSpeed() != 0.5 || h.GetPulse() != saved.GetPulse() {
+		t.Fatalf("restored speed %v and pulse %d, want %v and %d", h.GetSpeed(), h.GetPulse(), 0.5, saved.GetPulse())
+	}
+}
+
+func TestRestoreStateRejectsInvalidData(t *testing.T) {
+	h := newTestHandler(t)
+	valid := h.MarshalState()
+
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		want    tinygoerrors.ErrorCode
+	}{
+		{
+			name:    "short",
+			corrupt: func(data []byte) []byte { return data[:StateSize-1] },
+			want:    ErrorCodeESCMotorInvalidStateData,
+		},
+		{
+			name:    "version",
+			corrupt: func(data []byte) []byte { data[0] = StateVersion + 1; return data },
+			want:    ErrorCodeESCMotorInvalidStateData,
+		},
+		{
+			name:    "direction",
+			corrupt: func(data []byte) []byte { data[9] = 99; return data },
+			want:    ErrorCodeESCMotorInvalidStateData,
+		},
+		{
+			name: "pulse below min",
+			corrupt: func(data []byte) []byte {
+				binary.LittleEndian.PutUint32(data[10:14], DefaultMinPulseWidth-1)
+				return data
+			},
+			want: ErrorCodeESCMotorRawPulseOutOfRange,
+		},
+		{
+			name: "pulse above max",
+			corrupt: func(data []byte) []byte {
+				binary.LittleEndian.PutUint32(data[10:14], DefaultMaxPulseWidth+1)
+				return data
+			},
+			want: ErrorCodeESCMotorRawPulseOutOfRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				data := tt.corrupt(append([]byte(nil), valid...))
+				if errCode := h.RestoreState(data); errCode != tt.want {
+					t.Fatalf("RestoreState() = %v, want %v", errCode, tt.want)
+				}
+			},
+		)
+	}
+}
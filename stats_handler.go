@@ -0,0 +1,247 @@
+package tinygo_escmotor
+
+import (
+	"sync"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+type (
+	// Stats is a snapshot of the counters collected by a StatsHandler
+	Stats struct {
+		SetSpeedCalls     uint32
+		StopCalls         uint32
+		FailedCalls       uint32
+		Ramps             uint32
+		TotalRampDuration time.Duration
+		DirectionChanges  uint32
+	}
+
+	// motionCounter is the interface implemented by the handlers that count their ramps and neutral detours
+	motionCounter interface {
+		GetRampCount() uint32
+		GetTotalRampDuration() time.Duration
+		GetNeutralDetourCount() uint32
+	}
+
+	// motionCounts is a snapshot of the counters of a motionCounter
+	motionCounts struct {
+		ramps             uint32
+		totalRampDuration time.Duration
+		neutralDetours    uint32
+	}
+
+	// StatsHandler is the Handler decorator that counts the commands passed through to the wrapped handler, for
+	// performance tuning.
+	StatsHandler struct {
+		handler Handler
+		mutex   sync.Mutex
+		stats   Stats
+	}
+)
+
+// NewStatsHandler creates a new instance of StatsHandler
+//
+// Parameters:
+//
+// handler: The handler to wrap
+//
+// Returns:
+//
+// An instance of StatsHandler and an error if the handler is nil
+func NewStatsHandler(handler Handler) (*StatsHandler, tinygoerrors.ErrorCode) {
+	// Check if the handler is nil
+	if handler == nil {
+		return nil, ErrorCodeESCMotorNilHandler
+	}
+
+	return &StatsHandler{
+		handler: handler,
+	}, tinygoerrors.ErrorCodeNil
+}
+
+// AverageRampDuration returns the mean duration of the recorded ramps.
+//
+// Returns:
+//
+// The average ramp duration, zero if no ramp has been recorded
+func (s Stats) AverageRampDuration() time.Duration {
+	if s.Ramps == 0 {
+		return 0
+	}
+	return s.TotalRampDuration / time.Duration(s.Ramps)
+}
+
+// counts returns a snapshot of the ramps and neutral detours counted by the wrapped handler
+//
+// Returns:
+//
+// The counters of the wrapped handler, zero if it does not count them
+func (s *StatsHandler) counts() motionCounts {
+	counter, ok := s.handler.(motionCounter)
+	if !ok {
+		return motionCounts{}
+	}
+	return motionCounts{
+		ramps:             counter.GetRampCount(),
+		totalRampDuration: counter.GetTotalRampDuration(),
+		neutralDetours:    counter.GetNeutralDetourCount(),
+	}
+}
+
+// record updates the counters after a command passed through to the wrapped handler
+//
+// Parameters:
+//
+// previous: The counters of the wrapped handler before the command
+// errCode: The error returned by the command
+func (s *StatsHandler) record(previous motionCounts, errCode tinygoerrors.ErrorCode) {
+	current := s.counts()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if errCode != tinygoerrors.ErrorCodeNil {
+		s.stats.FailedCalls++
+	}
+
+	// Add the ramps and the neutral detours the command ran
+	s.stats.Ramps += current.ramps - previous.ramps
+	s.stats.TotalRampDuration += current.totalRampDuration - previous.totalRampDuration
+	s.stats.DirectionChanges += current.neutralDetours - previous.neutralDetours
+}
+
+// setSpeed counts a speed command and passes it through to the wrapped handler
+//
+// Parameters:
+//
+// set: The function setting the speed on the wrapped handler
+//
+// Returns:
+//
+// The error returned by the wrapped handler
+func (s *StatsHandler) setSpeed(set func() tinygoerrors.ErrorCode) tinygoerrors.ErrorCode {
+	s.mutex.Lock()
+	s.stats.SetSpeedCalls++
+	s.mutex.Unlock()
+
+	previous := s.counts()
+	errCode := set()
+	s.record(previous, errCode)
+	return errCode
+}
+
+// GetSpeed returns the current speed of the wrapped handler.
+//
+// Returns:
+//
+// The current speed
+func (s *StatsHandler) GetSpeed() float64 {
+	return s.handler.GetSpeed()
+}
+
+// Stop stops the wrapped handler.
+//
+// Returns:
+//
+// An error if the speed could not be set to 0, otherwise nil.
+func (s *StatsHandler) Stop() tinygoerrors.ErrorCode {
+	s.mutex.Lock()
+	s.stats.StopCalls++
+	s.mutex.Unlock()
+
+	previous := s.counts()
+	errCode := s.handler.Stop()
+	s.record(previous, errCode)
+	return errCode
+}
+
+// SetSpeed sets the speed of the wrapped handler.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (s *StatsHandler) SetSpeed(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	return s.setSpeed(
+		func() tinygoerrors.ErrorCode {
+			return s.handler.SetSpeed(speed, direction)
+		},
+	)
+}
+
+// SetSpeedForward sets the forward speed of the wrapped handler.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (s *StatsHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	return s.setSpeed(
+		func() tinygoerrors.ErrorCode {
+			return s.handler.SetSpeedForward(speed)
+		},
+	)
+}
+
+// SetSpeedBackward sets the backward speed of the wrapped handler.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (s *StatsHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
+	return s.setSpeed(
+		func() tinygoerrors.ErrorCode {
+			return s.handler.SetSpeedBackward(speed)
+		},
+	)
+}
+
+// IsStopped returns whether the wrapped handler is stopped.
+//
+// Returns:
+//
+// True if the motor is stopped, otherwise false
+func (s *StatsHandler) IsStopped() bool {
+	return s.handler.IsStopped()
+}
+
+// IsMoving returns whether the wrapped handler is moving.
+//
+// Returns:
+//
+// True if the motor is moving, otherwise false
+func (s *StatsHandler) IsMoving() bool {
+	return s.handler.IsMoving()
+}
+
+// GetStats returns a snapshot of the collected counters. The ramps and the direction changes passing through neutral
+// are only collected when the wrapped handler counts them, like DefaultHandler does, every ramp of a command being
+// accounted, including the ramp to neutral of a direction change.
+//
+// Returns:
+//
+// The collected counters
+func (s *StatsHandler) GetStats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+// ResetStats clears the collected counters.
+func (s *StatsHandler) ResetStats() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats = Stats{}
+}
@@ -0,0 +1,111 @@
+package tinygo_escmotor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+// newTestStatsHandler creates a stats handler wrapping a dry run handler on a fake clock
+//
+// Parameters:
+//
+// t: The test
+// opts: The options of the wrapped handler
+//
+// Returns:
+//
+// The stats handler
+func newTestStatsHandler(t *testing.T, opts ...Option) *StatsHandler {
+	t.Helper()
+	s, errCode := NewStatsHandler(newTestHandler(t, opts...))
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewStatsHandler() = %v", errCode)
+	}
+	return s
+}
+
+func TestStatsHandlerCountsRamps(t *testing.T) {
+	var isMovementDisabled atomic.Bool
+	s := newTestStatsHandler(
+		t,
+		WithPulseStep(50),
+		WithMovementEnabled(
+			func() bool {
+				return !isMovementDisabled.Load()
+			},
+		),
+	)
+
+	mustSucceed(t, s.SetSpeedForward(0.5))
+	stats := s.GetStats()
+	if stats.Ramps != 1 || stats.TotalRampDuration <= 0 {
+		t.Fatalf("GetStats() = %+v, want one timed ramp", stats)
+	}
+
+	// No ramp runs while movement is disabled, even if the commanded speed changes
+	isMovementDisabled.Store(true)
+	_ = s.SetSpeedForward(0.8)
+	if got := s.GetStats(); got.Ramps != 1 || got.TotalRampDuration != stats.TotalRampDuration {
+		t.Fatalf("GetStats() = %+v while movement is disabled, want %+v", got, stats)
+	}
+	if got := s.GetStats().SetSpeedCalls; got != 2 {
+		t.Fatalf("SetSpeedCalls = %d, want 2", got)
+	}
+}
+
+func TestStatsHandlerCountsNeutralDetours(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantRamps uint32
+		wantTurns uint32
+	}{
+		{
+			name:      "reversal",
+			wantRamps: 3,
+			wantTurns: 1,
+		},
+		{
+			name:      "instant reversal",
+			opts:      []Option{WithDirectInvert(true)},
+			wantRamps: 2,
+			wantTurns: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				s := newTestStatsHandler(t, append([]Option{WithDirectionDelays(time.Second, time.Second)}, tt.opts...)...)
+				mustSucceed(t, s.SetSpeedForward(0.5))
+				mustSucceed(t, s.SetSpeedBackward(0.5))
+
+				stats := s.GetStats()
+				if stats.Ramps != tt.wantRamps || stats.DirectionChanges != tt.wantTurns {
+					t.Fatalf(
+						"Ramps = %d and DirectionChanges = %d, want %d and %d",
+						stats.Ramps,
+						stats.DirectionChanges,
+						tt.wantRamps,
+						tt.wantTurns,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestStatsHandlerCountsFailedCalls(t *testing.T) {
+	s := newTestStatsHandler(t)
+	if errCode := s.SetSpeed(2, DirectionForward); errCode != ErrorCodeESCMotorSpeedOutOfRange {
+		t.Fatalf("SetSpeed(2) = %v, want ErrorCodeESCMotorSpeedOutOfRange", errCode)
+	}
+	mustSucceed(t, s.Stop())
+
+	stats := s.GetStats()
+	if stats.SetSpeedCalls != 1 || stats.StopCalls != 1 || stats.FailedCalls != 1 || stats.Ramps != 0 {
+		t.Fatalf("GetStats() = %+v, want one failed SetSpeed call, one stop and no ramp", stats)
+	}
+}
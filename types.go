@@ -1,6 +1,8 @@
 package tinygo_escmotor
 
 import (
+	"math"
+	"sync"
 	"time"
 
 	"machine"
@@ -12,10 +14,18 @@ import (
 
 type (
 	// DefaultHandler is the default implementation to handle ESC (Electronic Speed Controller) motor operations.
+	//
+	// It is safe for concurrent use. The commands run one at a time, so a command blocks while another one ramps,
+	// sleeps or holds a pulse width, and the getters only wait for the brief bookkeeping updates, never for a ramp. The
+	// callbacks run while the command holds the handler, so they may call the getters but must not issue commands.
 	DefaultHandler struct {
+		commandMutex           sync.Mutex
+		stateMutex             sync.RWMutex
 		afterSetSpeedFunc      func(speed float64)
 		isMovementEnabled      func() bool
 		isPolarityInverted     bool
+		isSignalInverted       bool
+		isUnidirectional       bool
 		frequency              uint16
 		minPulseWidth          uint32
 		neutralPulseWidth      uint32
@@ -25,25 +35,288 @@ type (
 		maxForwardSpeed        float64
 		maxBackwardSpeed       float64
 		pulse                  uint32
+		peakSpeed              float64
+		peakPulse              uint32
 		pulseStep              *uint32
+		rampTarget             uint32
+		rampStep               uint32
+		rampDuration           time.Duration
+		throttleCurve          ThrottleCurve
+		customThrottleCurve    func(input float64) float64
 		logger                 tinygologger.Logger
 		lastUpdate             time.Time
 		backwardToForwardDelay time.Duration
 		forwardToBackwardDelay time.Duration
+		zeroCrossDwell         time.Duration
+		delayScale             float64
+		isNeutralLocked        bool
+		forwardStartOffset     uint32
+		backwardStartOffset    uint32
 		lastStopTime           time.Time
 		pwm                    tinygopwm.PWM
 		period                 uint32
 		periodDelay            time.Duration
 		channel                uint8
+		onError                func(errCode tinygoerrors.ErrorCode, op string)
+		events                 chan Event
+		rampCancel             chan struct{}
+		rampDone               chan struct{}
+		isArmRequired          bool
+		isArmed                bool
+		failsafeTimeout        time.Duration
+		onFailsafe             func()
+		lastFeed               time.Time
+		isFailsafeActive       bool
+		watchdogStop           chan struct{}
+		watchdogDone           chan struct{}
+		isClosed               bool
+		isBrakeMode            bool
+		deadband               float64
+		is3DMode               bool
+		motorPoles             uint8
+		rpmSource              func() (uint32, bool)
+		rpm                    uint32
+		currentLimit           float64
+		currentSource          func() float64
+		onCurrentLimit         func()
+		isCurrentLimited       bool
+		currentCeiling         float64
+		thermalSource          func() float64
+		thermalStartTemp       float64
+		thermalMaxTemp         float64
+		thermalCeiling         float64
+		softStartRate          float64
+		softStartDuration      time.Duration
+		isSoftStartSkipped     bool
+		onPulseStep            func(pulse uint32)
+		logLevels              [logCategoryEnd]LogLevel
+		isDryRun               bool
+		pulseHistory           []uint32
+		writeDuty              func(pulse uint32) error
+		isManualOverride       bool
+		forwardPulseStep       *uint32
+		backwardPulseStep      *uint32
+		maxRampTime            time.Duration
+		lastRampDuration       time.Duration
+		rampCount              uint32
+		totalRampDuration      time.Duration
+		neutralDetourCount     uint32
+		isDirectInvert         bool
+		baseNeutralPulseWidth  uint32
+		neutralTrim            int32
+		clock                  clock
+		isVerbosePulseLogging  bool
+		isDeferredLogging      bool
+		stopPulse              StopPulse
+		isRecording            bool
+		recordStart            time.Time
+		recordedSamples        []PulseSample
+		extraChannels          []uint8
+		cruiseStop             chan struct{}
+		cruiseDone             chan struct{}
+		minEffectiveSpeed      float64
+		isThrottleSideSwapped  bool
+		isStopRamp             bool
+		stopRampDuration       time.Duration
+		inputSmoothing         float64
+		filteredSpeed          float64
+		floatPrecision         int
+		isMovementObserved     bool
+		boostThreshold         float64
+		isBoostEnabled         bool
+		idleKeepAlive          time.Duration
+		keepAliveStop          chan struct{}
+		keepAliveDone          chan struct{}
+		isPulseWritten         bool
+	}
+
+	// pwmEnabler is the interface implemented by the PWM peripherals that can disable their output
+	pwmEnabler interface {
+		Enable(enable bool)
+	}
+
+	// pwmDutyReader is the interface implemented by the PWM peripherals that can read back the value of a channel
+	pwmDutyReader interface {
+		Get(channel uint8) uint32
+	}
+
+	// speedCommand is a speed command resolved into the physical direction and pulse width to drive
+	speedCommand struct {
+		speed         float64
+		signedSpeed   float64
+		direction     Direction
+		pulse         uint32
+		filteredSpeed float64
+		errCode       tinygoerrors.ErrorCode
+	}
+
+	// Status is a snapshot of the state of the handler.
+	Status struct {
+		Speed              float64
+		SignedSpeed        float64
+		Direction          Direction
+		Pulse              uint32
+		NeutralPulseWidth  uint32
+		IsArmed            bool
+		IsFailsafeActive   bool
+		IsNeutralLocked    bool
+		IsCurrentLimited   bool
+		IsManualOverride   bool
+		IsClosed           bool
+		RemainingRampSteps uint32
+	}
+
+	// PulseSample is a pulse width written by the handler while recording, with the time elapsed since the recording
+	// started.
+	PulseSample struct {
+		Elapsed time.Duration
+		Pulse   uint32
+	}
+
+	// BeepStep is a step of a beep pattern, a pulse width held for a duration.
+	BeepStep struct {
+		Pulse    uint32
+		Duration time.Duration
+	}
+
+	// Event is a state-change event emitted by the handler.
+	Event struct {
+		Type      EventType
+		Speed     float64
+		Direction Direction
+		Pulse     uint32
+		ErrorCode tinygoerrors.ErrorCode
 	}
 )
 
 const (
-	// Float64Precision is the precision for float64 values in log messages
+	// Float64Precision is the default precision for float64 values in log messages
 	Float64Precision = 3
+
+	// EventBufferSize is the size of the buffered events channel, events are dropped when it is full
+	EventBufferSize = 16
+
+	// CurrentLimitHysteresis is the fraction of the current limit the sensed current must drop below to release the
+	// current limit protection
+	CurrentLimitHysteresis = 0.9
+
+	// CurrentLimitPullbackStep is the speed the current limit protection pulls back on every check while the sensed
+	// current exceeds the limit
+	CurrentLimitPullbackStep = 0.05
+
+	// CurrentLimitCheckInterval is the interval of the background current limit checks
+	CurrentLimitCheckInterval = 20 * time.Millisecond
+
+	// TypicalMinPulseWidth is the lowest pulse width of the usual servo range in nanoseconds (500µs), lower standard
+	// pulse widths are warned about on creation
+	TypicalMinPulseWidth uint32 = 500000
+
+	// TypicalMaxPulseWidth is the highest pulse width of the usual servo range in nanoseconds (2500µs), higher standard
+	// pulse widths are warned about on creation
+	TypicalMaxPulseWidth uint32 = 2500000
+
+	// NeutralOffCenterTolerance is the fraction of the pulse width span the forward and backward spans may differ by
+	// before the neutral pulse width is warned about on creation
+	NeutralOffCenterTolerance = 0.1
+
+	// NeutralCalibrationStep is the neutral trim step between two trials of the neutral calibration, in nanoseconds
+	// (1µs)
+	NeutralCalibrationStep uint32 = 1000
+
+	// NeutralCalibrationSettleTime is the time every trial of the neutral calibration holds its pulse width before
+	// reading the RPM, so the motor settles
+	NeutralCalibrationSettleTime = 200 * time.Millisecond
+
+	// SelfTestTolerance is the largest difference between the value read back from a channel and the expected one that
+	// SelfTest accepts, in PWM counter ticks
+	SelfTestTolerance uint32 = 1
+)
+
+const (
+	// OpSetSpeed is the operation label reported when SetSpeed fails
+	OpSetSpeed = "SetSpeed"
+
+	// OpSetSpeedForward is the operation label reported when SetSpeedForward fails
+	OpSetSpeedForward = "SetSpeedForward"
+
+	// OpSetSpeedBackward is the operation label reported when SetSpeedBackward fails
+	OpSetSpeedBackward = "SetSpeedBackward"
+
+	// OpStop is the operation label reported when Stop fails
+	OpStop = "Stop"
+
+	// OpCalibrate is the operation label reported when Calibrate fails
+	OpCalibrate = "Calibrate"
+
+	// OpCalibrateNeutral is the operation label reported when CalibrateNeutral fails
+	OpCalibrateNeutral = "CalibrateNeutral"
+
+	// OpArm is the operation label reported when Arm fails
+	OpArm = "Arm"
+
+	// OpDisarm is the operation label reported when Disarm fails
+	OpDisarm = "Disarm"
+
+	// OpFailsafe is the operation label reported when the failsafe watchdog fails to stop the motor
+	OpFailsafe = "Failsafe"
+
+	// OpClose is the operation label reported when Close fails to ramp the motor to neutral
+	OpClose = "Close"
+
+	// OpBrake is the operation label reported when Brake fails
+	OpBrake = "Brake"
+
+	// OpEmergencyStop is the operation label reported when EmergencyStop fails
+	OpEmergencyStop = "EmergencyStop"
+
+	// OpSetThrottle is the operation label reported when SetThrottle fails
+	OpSetThrottle = "SetThrottle"
+
+	// OpCurrentLimit is the operation label reported when the current limit protection fails to pull back the motor
+	OpCurrentLimit = "CurrentLimit"
+
+	// OpReset is the operation label reported when Reset fails
+	OpReset = "Reset"
+
+	// OpSetRawPulse is the operation label reported when SetRawPulse fails
+	OpSetRawPulse = "SetRawPulse"
+
+	// OpCoast is the operation label reported when Coast fails
+	OpCoast = "Coast"
+
+	// OpSetSpeedAsync is the operation label reported when SetSpeedAsync or its background ramp fails
+	OpSetSpeedAsync = "SetSpeedAsync"
+
+	// OpPlayBeep is the operation label reported when PlayBeep fails
+	OpPlayBeep = "PlayBeep"
+
+	// OpSelfTest is the operation label reported when SelfTest fails
+	OpSelfTest = "SelfTest"
+
+	// OpStopWithRamp is the operation label reported when StopWithRamp fails
+	OpStopWithRamp = "StopWithRamp"
+
+	// OpCruise is the operation label reported when the cruise mode fails to re-assert the latched speed
+	OpCruise = "Cruise"
+
+	// OpReplay is the operation label reported when Replay fails
+	OpReplay = "Replay"
+
+	// OpSetPolarityInverted is the operation label reported when SetPolarityInverted fails
+	OpSetPolarityInverted = "SetPolarityInverted"
+
+	// OpRestoreState is the operation label reported when RestoreState fails
+	OpRestoreState = "RestoreState"
 )
 
 var (
+	// closedChannel is an already closed channel returned when there is no in-flight async ramp
+	closedChannel = func() chan struct{} {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}()
+
 	// setPeriodPrefix is the prefix for the log message when setting the PWM period
 	setPeriodPrefix = []byte("Set ESC Motor PWM period to:")
 
@@ -58,9 +331,93 @@ var (
 
 	// setPulseWidthPrefix is the prefix for the log message when gradually setting the pulse width
 	setPulseWidthPrefix = []byte("Set ESC Motor pulse width to:")
+
+	// selfTestPrefix is the prefix for the log message of every phase of the self-test
+	selfTestPrefix = []byte("Self-test ESC Motor, pulse width:")
+
+	// selfTestFailedPrefix is the prefix for the log message when the read-back value of a self-test phase mismatches
+	selfTestFailedPrefix = []byte("Self-test ESC Motor failed, read-back value:")
+
+	// clampPulseWidthPrefix is the prefix for the log message when a pulse width outside the bounds is clamped
+	clampPulseWidthPrefix = []byte("Clamp ESC Motor pulse width out of bounds:")
+
+	// rampFromPrefix is the prefix for the log message summarizing a completed ramp
+	rampFromPrefix = []byte("Ramped ESC Motor pulse width from:")
+
+	// rampToPrefix is the prefix for the target part of the log message summarizing a completed ramp
+	rampToPrefix = []byte("to:")
+
+	// rampStepsPrefix is the prefix for the steps part of the log message summarizing a completed ramp
+	rampStepsPrefix = []byte("in steps:")
+
+	// retryConfigurePWMPrefix is the prefix for the log message when retrying the PWM configuration
+	retryConfigurePWMPrefix = []byte("Retry ESC Motor PWM configuration, failed attempt:")
+
+	// calibrateHighPrefix is the prefix for the log message when driving the max pulse width during calibration
+	calibrateHighPrefix = []byte("Calibrate ESC Motor high endpoint, pulse width:")
+
+	// calibrateLowPrefix is the prefix for the log message when driving the min pulse width during calibration
+	calibrateLowPrefix = []byte("Calibrate ESC Motor low endpoint, pulse width:")
+
+	// calibrateNeutralPrefix is the prefix for the log message when returning to neutral after calibration
+	calibrateNeutralPrefix = []byte("Calibrate ESC Motor done, neutral pulse width:")
+
+	// calibrateNeutralTrialPrefix is the prefix for the log message of every trial of the neutral calibration
+	calibrateNeutralTrialPrefix = []byte("Calibrate ESC Motor neutral trial, pulse width:")
+
+	// calibrateNeutralRPMPrefix is the prefix for the RPM part of the log message of a neutral calibration trial
+	calibrateNeutralRPMPrefix = []byte("RPM:")
+
+	// armPrefix is the prefix for the log message when the motor is armed
+	armPrefix = []byte("Arm ESC Motor")
+
+	// disarmPrefix is the prefix for the log message when the motor is disarmed
+	disarmPrefix = []byte("Disarm ESC Motor")
+
+	// failsafePrefix is the prefix for the log message when the failsafe watchdog stops the motor
+	failsafePrefix = []byte("ESC Motor failsafe triggered, no command received in time")
+
+	// coastPrefix is the prefix for the log message when the motor is left coasting
+	coastPrefix = []byte("Coast ESC Motor")
+
+	// emergencyStopPrefix is the prefix for the log message when the motor is emergency stopped
+	emergencyStopPrefix = []byte("Emergency stop ESC Motor")
+
+	// brakePrefix is the prefix for the log message when braking the forward motion
+	brakePrefix = []byte("Brake ESC Motor with force:")
+
+	// lockNeutralPrefix is the prefix for the log message when locking the motor at neutral
+	lockNeutralPrefix = []byte("Lock ESC Motor at neutral")
+
+	// unlockNeutralPrefix is the prefix for the log message when releasing the neutral lock
+	unlockNeutralPrefix = []byte("Unlock ESC Motor from neutral")
+
+	// currentLimitPrefix is the prefix for the log message when the sensed current exceeds the current limit
+	currentLimitPrefix = []byte("ESC Motor current limit exceeded, sensed current:")
+
+	// thermalDeratePrefix is the prefix for the log message when the thermal derating lowers the speed ceiling
+	thermalDeratePrefix = []byte("Derate ESC Motor speed ceiling for temperature, ceiling:")
+
+	// thermalRecoverPrefix is the prefix for the log message when the thermal derating returns to normal
+	thermalRecoverPrefix = []byte("ESC Motor temperature back to normal, derating released")
+
+	// asymmetricMode3DPrefix is the prefix for the log message when the 3D mode pulse widths are not symmetric
+	asymmetricMode3DPrefix = []byte("ESC Motor 3D mode pulse widths are not symmetric around neutral:")
+
+	// warnPulseNearPeriodPrefix is the prefix for the log message when the max pulse width takes most of the period
+	warnPulseNearPeriodPrefix = []byte("ESC Motor max pulse width takes more than half of the PWM period:")
+
+	// warnPulseWidthRangePrefix is the prefix for the log message when the pulse widths are outside the usual servo
+	// range
+	warnPulseWidthRangePrefix = []byte("ESC Motor pulse widths are outside the usual servo range, min pulse width:")
+
+	// warnNeutralOffCenterPrefix is the prefix for the log message when the neutral pulse width is off center
+	warnNeutralOffCenterPrefix = []byte("ESC Motor neutral pulse width is off center between the min and max:")
 )
 
-// NewDefaultHandler creates a new instance of DefaultHandler
+// NewDefaultHandler creates a new instance of DefaultHandler. The constructor stops the motor through Stop, which
+// ramps gradually to the stop pulse width when a pulse step is set and blocks while it sleeps between the steps, see
+// NewHandler with WithDeferredInitialStop to avoid it.
 //
 // Parameters:
 //
@@ -76,8 +433,8 @@ var (
 // maxForwardSpeed: The maximum forward percentage speed value for the motor
 // maxBackwardSpeed: The maximum backward percentage speed value for the motor
 // pulseStep: Step value for gradually changing the pulse width
-// backwardToForwardDelay: Delay when changing direction from backward to forward
-// forwardToBackwardDelay: Delay when changing direction from forward to backward
+// backwardToForwardDelay: Delay when changing direction from backward to forward, zero or positive
+// forwardToBackwardDelay: Delay when changing direction from forward to backward, zero or positive
 // logger: The logger to log messages
 //
 // Returns:
@@ -99,288 +456,4349 @@ func NewDefaultHandler(
 	backwardToForwardDelay time.Duration,
 	forwardToBackwardDelay time.Duration,
 	logger tinygologger.Logger,
+) (*DefaultHandler, tinygoerrors.ErrorCode) {
+	return NewDefaultHandlerFromConfig(
+		pwm,
+		pin,
+		Config{
+			Frequency:              frequency,
+			MinPulseWidth:          minPulseWidth,
+			NeutralPulseWidth:      neutralPulseWidth,
+			MaxPulseWidth:          maxPulseWidth,
+			IsPolarityInverted:     isPolarityInverted,
+			MaxForwardSpeed:        maxForwardSpeed,
+			MaxBackwardSpeed:       maxBackwardSpeed,
+			PulseStep:              pulseStep,
+			BackwardToForwardDelay: backwardToForwardDelay,
+			ForwardToBackwardDelay: forwardToBackwardDelay,
+		},
+		afterSetSpeedFunc,
+		isMovementEnabled,
+		logger,
+	)
+}
+
+// NewDefaultHandlerFromConfig creates a new instance of DefaultHandler from a tuning profile. Like NewDefaultHandler,
+// it stops the motor through Stop, which may block while ramping.
+//
+// Parameters:
+//
+// pwm: The PWM interface to control the ESC motor
+// pin: The pin connected to the ESC motor
+// tuning: The tuning profile of the ESC motor
+// afterSetSpeedFunc: Function to call after setting the speed
+// isMovementEnabled: Function to check if movement is enabled
+// logger: The logger to log messages
+//
+// Returns:
+//
+// An instance of DefaultHandler and an error if any occurred during initialization
+func NewDefaultHandlerFromConfig(
+	pwm tinygopwm.PWM,
+	pin machine.Pin,
+	tuning Config,
+	afterSetSpeedFunc func(speed float64),
+	isMovementEnabled func() bool,
+	logger tinygologger.Logger,
+) (*DefaultHandler, tinygoerrors.ErrorCode) {
+	return newHandler(
+		pwm,
+		pin,
+		&config{
+			Config:            tuning,
+			afterSetSpeedFunc: afterSetSpeedFunc,
+			isMovementEnabled: isMovementEnabled,
+			logger:            logger,
+			floatPrecision:    Float64Precision,
+		},
+	)
+}
+
+// newHandler creates a new instance of DefaultHandler, validating the configuration once
+//
+// Parameters:
+//
+// pwm: The PWM interface to control the ESC motor
+// pin: The pin connected to the ESC motor
+// cfg: The configuration of the ESC motor
+//
+// Returns:
+//
+// An instance of DefaultHandler and an error if any occurred during initialization
+func newHandler(
+	pwm tinygopwm.PWM,
+	pin machine.Pin,
+	cfg *config,
 ) (*DefaultHandler, tinygoerrors.ErrorCode) {
 	// Check if the frequency is zero
-	if frequency == 0 {
+	if cfg.Frequency == 0 {
 		return nil, ErrorCodeESCMotorZeroFrequency
 	}
 
-	// Configure the PWM
-	period := 1e9 / float64(frequency)
-	if err := pwm.Configure(
-		machine.PWMConfig{
-			Period: uint64(period),
-		},
-	); err != nil {
-		return nil, ErrorCodeESCMotorFailedToConfigurePWM
+	// Check if the protocol is supported
+	if cfg.protocol != ProtocolNil && cfg.protocol.Frequency() == 0 {
+		return nil, ErrorCodeESCMotorUnsupportedProtocol
 	}
 
-	// Log the configured period
-	if logger != nil {
-		logger.AddMessageWithUint32(
-			setPeriodPrefix,
-			uint32(period),
-			true,
-			true,
-			false,
-		)
-		logger.Debug()
+	// Fall back to the wall clock
+	if cfg.clock == nil {
+		cfg.clock = realClock{}
 	}
 
-	// Get the channel from the pin
-	channel, err := pwm.Channel(pin)
-	if err != nil {
-		return nil, ErrorCodeESCMotorFailedToGetPWMChannel
+	// Configure the PWM unless running a dry run, which records the pulse widths instead of driving them
+	period := 1e9 / float64(cfg.Frequency)
+	var channel uint8
+	if !cfg.isDryRun {
+		var errCode tinygoerrors.ErrorCode
+		if channel, errCode = configurePWM(pwm, pin, period, cfg); errCode != tinygoerrors.ErrorCodeNil {
+			return nil, errCode
+		}
 	}
 
-	// Check if the neutral pulse width is within the valid range
-	if neutralPulseWidth < minPulseWidth || neutralPulseWidth > maxPulseWidth {
-		return nil, ErrorCodeESCMotorInvalidNeutralPulseWidth
+	// Check if the motor is unidirectional, in which case it stops at the min pulse width and cannot go backward
+	if cfg.IsUnidirectional {
+		if cfg.IsPolarityInverted {
+			return nil, ErrorCodeESCMotorBackwardNotSupported
+		}
+		cfg.NeutralPulseWidth = cfg.MinPulseWidth
 	}
 
-	// Check if the min pulse width is valid
-	if minPulseWidth == 0 || minPulseWidth >= neutralPulseWidth || minPulseWidth >= uint32(period) {
-		return nil, ErrorCodeESCMotorInvalidMinPulseWidth
+	// Check if the pulse widths are valid
+	if errCode := validatePulseWidths(
+		cfg.MinPulseWidth,
+		cfg.NeutralPulseWidth,
+		cfg.MaxPulseWidth,
+		uint32(period),
+		cfg.IsUnidirectional,
+	); errCode != tinygoerrors.ErrorCodeNil {
+		return nil, errCode
 	}
 
-	// Check if the max pulse width is valid
-	if maxPulseWidth == 0 || maxPulseWidth <= neutralPulseWidth || maxPulseWidth >= uint32(period) {
-		return nil, ErrorCodeESCMotorInvalidMaxPulseWidth
+	// Check if the 3D mode is used by a motor that can go backward
+	if cfg.is3DMode && cfg.IsUnidirectional {
+		return nil, ErrorCodeESCMotorBackwardNotSupported
+	}
+
+	// Check if the throttle sides are swapped on a motor that cannot go below neutral
+	if cfg.isThrottleSideSwapped && cfg.IsUnidirectional {
+		return nil, ErrorCodeESCMotorBackwardNotSupported
 	}
 
+	// Check if the stop pulse is valid, the min pulse width drives full reverse in 3D mode
+	if !cfg.stopPulse.IsValid() || (cfg.stopPulse == StopPulseMin && cfg.is3DMode) {
+		return nil, ErrorCodeESCMotorInvalidStopPulse
+	}
+
+	// Warn about the pulse widths that are valid but likely wrong
+	warnSuspiciousPulseWidths(cfg, uint32(period))
+
 	// Check if the max forward speed is valid
-	if maxForwardSpeed <= 0 || maxForwardSpeed > 1 {
+	if cfg.MaxForwardSpeed <= 0 || cfg.MaxForwardSpeed > 1 {
 		return nil, ErrorCodeESCMotorInvalidMaxForwardSpeed
 	}
 
 	// Check if the max backward speed is valid
-	if maxBackwardSpeed <= 0 || maxBackwardSpeed > 1 {
+	if cfg.MaxBackwardSpeed <= 0 || cfg.MaxBackwardSpeed > 1 {
 		return nil, ErrorCodeESCMotorInvalidMaxBackwardSpeed
 	}
 
+	// Check if the pulse step is zero, which would never reach the target
+	if (cfg.PulseStep != nil && *cfg.PulseStep == 0) ||
+		(cfg.forwardPulseStep != nil && *cfg.forwardPulseStep == 0) ||
+		(cfg.backwardPulseStep != nil && *cfg.backwardPulseStep == 0) {
+		return nil, ErrorCodeESCMotorInvalidPulseStep
+	}
+
+	// Check if the direction-change delays are valid
+	if cfg.BackwardToForwardDelay < 0 || cfg.ForwardToBackwardDelay < 0 {
+		return nil, ErrorCodeESCMotorInvalidDirectionDelay
+	}
+
+	// Check if the number of motor poles is valid
+	motorPoles := cfg.motorPoles
+	if motorPoles == 0 {
+		motorPoles = DefaultMotorPoles
+	}
+	if motorPoles%2 != 0 {
+		return nil, ErrorCodeESCMotorInvalidMotorPoles
+	}
+
+	// Check if the current limit is valid
+	if cfg.currentSource != nil && cfg.currentLimit <= 0 {
+		return nil, ErrorCodeESCMotorInvalidCurrentLimit
+	}
+
+	// Check if the thermal derating temperatures are valid
+	if cfg.thermalSource != nil && cfg.thermalMaxTemp <= cfg.thermalStartTemp {
+		return nil, ErrorCodeESCMotorInvalidThermalDerate
+	}
+
+	// Check if the soft start rate is valid
+	if cfg.softStartRate < 0 {
+		return nil, ErrorCodeESCMotorInvalidSoftStartRate
+	}
+
+	// Check if the deadband is valid
+	if cfg.deadband < 0 || cfg.deadband >= 1 {
+		return nil, ErrorCodeESCMotorInvalidDeadband
+	}
+
+	// Check if the min effective speed is valid
+	if cfg.minEffectiveSpeed < 0 || cfg.minEffectiveSpeed > 1 {
+		return nil, ErrorCodeESCMotorInvalidMinEffectiveSpeed
+	}
+
+	// Check if the input smoothing factor is valid
+	if cfg.inputSmoothing < 0 || cfg.inputSmoothing > 1 {
+		return nil, ErrorCodeESCMotorInvalidInputSmoothing
+	}
+
+	// Check if the boost threshold is valid
+	if cfg.boostThreshold < 0 || cfg.boostThreshold > 1 {
+		return nil, ErrorCodeESCMotorInvalidBoostThreshold
+	}
+
+	// Check if the idle keep-alive interval is valid
+	if cfg.idleKeepAlive < 0 {
+		return nil, ErrorCodeESCMotorInvalidIdleKeepAlive
+	}
+
+	// Check if the maximum ramp time is valid
+	if cfg.maxRampTime < 0 {
+		return nil, ErrorCodeESCMotorInvalidMaxRampTime
+	}
+
 	// Initialize the ESC motor with the provided parameters
 	handler := &DefaultHandler{
-		afterSetSpeedFunc:      afterSetSpeedFunc,
-		isMovementEnabled:      isMovementEnabled,
-		isPolarityInverted:     isPolarityInverted,
-		frequency:              frequency,
-		minPulseWidth:          minPulseWidth,
-		neutralPulseWidth:      neutralPulseWidth,
-		maxPulseWidth:          maxPulseWidth,
-		pulseStep:              pulseStep,
-		backwardToForwardDelay: backwardToForwardDelay,
-		forwardToBackwardDelay: forwardToBackwardDelay,
-		maxForwardSpeed:        maxForwardSpeed,
-		maxBackwardSpeed:       maxBackwardSpeed,
+		afterSetSpeedFunc:      cfg.afterSetSpeedFunc,
+		isMovementEnabled:      cfg.isMovementEnabled,
+		isPolarityInverted:     cfg.IsPolarityInverted,
+		isSignalInverted:       cfg.IsSignalInverted,
+		isUnidirectional:       cfg.IsUnidirectional,
+		frequency:              cfg.Frequency,
+		minPulseWidth:          cfg.MinPulseWidth,
+		neutralPulseWidth:      cfg.NeutralPulseWidth,
+		maxPulseWidth:          cfg.MaxPulseWidth,
+		pulseStep:              cfg.PulseStep,
+		backwardToForwardDelay: cfg.BackwardToForwardDelay,
+		forwardToBackwardDelay: cfg.ForwardToBackwardDelay,
+		delayScale:             1,
+		maxForwardSpeed:        cfg.MaxForwardSpeed,
+		maxBackwardSpeed:       cfg.MaxBackwardSpeed,
 		speed:                  0,
-		pulse:                  neutralPulseWidth,
-		logger:                 logger,
+		direction:              DirectionStop,
+		pulse:                  cfg.NeutralPulseWidth,
+		rampTarget:             cfg.NeutralPulseWidth,
+		peakPulse:              cfg.NeutralPulseWidth,
+		logger:                 cfg.logger,
 		pwm:                    pwm,
 		channel:                channel,
 		period:                 uint32(period),
 		periodDelay:            time.Duration(period),
+		events:                 make(chan Event, EventBufferSize),
+		isArmRequired:          cfg.isArmRequired,
+		rampDuration:           cfg.rampDuration,
+		throttleCurve:          cfg.throttleCurve,
+		customThrottleCurve:    cfg.customThrottleCurve,
+		failsafeTimeout:        cfg.failsafeTimeout,
+		onFailsafe:             cfg.onFailsafe,
+		isBrakeMode:            cfg.isBrakeMode,
+		deadband:               cfg.deadband,
+		is3DMode:               cfg.is3DMode,
+		motorPoles:             motorPoles,
+		currentLimit:           cfg.currentLimit,
+		currentSource:          cfg.currentSource,
+		onCurrentLimit:         cfg.onCurrentLimit,
+		currentCeiling:         1,
+		thermalSource:          cfg.thermalSource,
+		thermalStartTemp:       cfg.thermalStartTemp,
+		thermalMaxTemp:         cfg.thermalMaxTemp,
+		thermalCeiling:         1,
+		softStartRate:          cfg.softStartRate,
+		onPulseStep:            cfg.onPulseStep,
+		logLevels:              cfg.logLevels,
+		isDryRun:               cfg.isDryRun,
+		forwardPulseStep:       cfg.forwardPulseStep,
+		backwardPulseStep:      cfg.backwardPulseStep,
+		maxRampTime:            cfg.maxRampTime,
+		isDirectInvert:         cfg.isDirectInvert,
+		baseNeutralPulseWidth:  cfg.NeutralPulseWidth,
+		clock:                  cfg.clock,
+		isVerbosePulseLogging:  cfg.isVerbosePulseLogging,
+		isDeferredLogging:      cfg.isDeferredLogging,
+		stopPulse:              cfg.stopPulse,
+		minEffectiveSpeed:      cfg.minEffectiveSpeed,
+		isThrottleSideSwapped:  cfg.isThrottleSideSwapped,
+		inputSmoothing:         cfg.inputSmoothing,
+		floatPrecision:         int(cfg.floatPrecision),
+		isMovementObserved:     true,
+		boostThreshold:         cfg.boostThreshold,
+		idleKeepAlive:          cfg.idleKeepAlive,
+	}
+
+	// Set the duty writer, a dry run records the pulse widths instead of driving them
+	switch {
+	case cfg.writeDuty != nil:
+		handler.writeDuty = cfg.writeDuty
+	case cfg.isDryRun:
+		handler.writeDuty = handler.recordDuty
+	default:
+		handler.writeDuty = handler.writePWMDuty
+	}
+
+	// Stop the motor initially, through Stop unless the initial stop is deferred to a direct write or skipped
+	switch {
+	case cfg.isInitialStopSkipped:
+	case cfg.isDeferredInitialStop:
+		_ = handler.writePulseWidth(handler.stopPulseWidth())
+	default:
+		_ = handler.Stop()
 	}
 
-	// Stop the motor initially
-	_ = handler.Stop()
+	// Start the failsafe and current limit watchdog
+	if handler.failsafeTimeout > 0 || handler.currentSource != nil {
+		handler.watchdogStop = make(chan struct{})
+		handler.watchdogDone = make(chan struct{})
+		go handler.runWatchdog()
+	}
+
+	// Start the idle keep-alive
+	if handler.idleKeepAlive > 0 {
+		handler.keepAliveStop = make(chan struct{})
+		handler.keepAliveDone = make(chan struct{})
+		go handler.runIdleKeepAlive()
+	}
 
 	return handler, tinygoerrors.ErrorCodeNil
 }
 
-// graduallySetPulseWidth gradually sets the pulse width to the pulse value
+// configurePWM configures the PWM with the period, retrying on transient failures, and gets the channel of the pin
 //
 // Parameters:
 //
-// pulse: The pulse pulse width value to set
-func (h *DefaultHandler) graduallySetPulseWidth(pulse uint32) {
-	// Gradually increment or decrement the pulse to the target value
-	if h.pulseStep != nil {
-		if h.pulse < pulse {
-			for i := h.pulse; i < pulse; i += *h.pulseStep {
-				// Log the gradual step
-				if h.logger != nil {
-					h.logger.AddMessageWithUint32(
-						setPulseWidthPrefix,
-						i,
-						true,
-						true,
-						false,
-					)
-					h.logger.Debug()
-				}
-				tinygopwm.SetDuty(h.pwm, h.channel, i, h.period)
-				time.Sleep(h.periodDelay)
-
-				// Update the stop time if it is set to neutral
-				if i == h.neutralPulseWidth {
-					h.lastStopTime = time.Now()
-				}
-			}
-		} else if h.pulse > pulse {
-			for i := h.pulse; i > pulse; i -= *h.pulseStep {
-				// Log the gradual step
-				if h.logger != nil {
-					h.logger.AddMessageWithUint32(
-						setPulseWidthPrefix,
-						i,
-						true,
-						true,
-						false,
-					)
-					h.logger.Debug()
-				}
-				tinygopwm.SetDuty(h.pwm, h.channel, i, h.period)
-				time.Sleep(h.periodDelay)
-
-				// Update the stop time if it is set to neutral
-				if i == h.neutralPulseWidth {
-					h.lastStopTime = time.Now()
-				}
-			}
+// pwm: The PWM interface to control the ESC motor
+// pin: The pin connected to the ESC motor
+// period: The PWM period in nanoseconds
+// cfg: The configuration of the ESC motor
+//
+// Returns:
+//
+// The PWM channel of the pin and an error if the PWM could not be configured or the channel could not be found
+func configurePWM(
+	pwm tinygopwm.PWM,
+	pin machine.Pin,
+	period float64,
+	cfg *config,
+) (uint8, tinygoerrors.ErrorCode) {
+	// Configure the PWM, retrying on transient failures
+	for attempt := uint8(0); ; attempt++ {
+		err := pwm.Configure(
+			machine.PWMConfig{
+				Period: uint64(period),
+			},
+		)
+		if err == nil {
+			break
+		}
+		if attempt >= cfg.PWMConfigureRetries {
+			return 0, ErrorCodeESCMotorFailedToConfigurePWM
+		}
+
+		// Log the failed attempt
+		if cfg.logger != nil {
+			cfg.logger.AddMessageWithUint8(
+				retryConfigurePWMPrefix,
+				attempt+1,
+				true,
+				true,
+				false,
+			)
+			cfg.logger.Warning()
 		}
+		cfg.clock.Sleep(cfg.PWMConfigureRetryDelay)
 	}
 
-	// Log the final pulse
-	if h.logger != nil {
-		h.logger.AddMessageWithUint32(
-			setPulseWidthPrefix,
-			pulse,
+	// Log the configured period
+	if cfg.logger != nil {
+		cfg.logger.AddMessageWithUint32(
+			setPeriodPrefix,
+			uint32(period),
 			true,
 			true,
 			false,
 		)
-		h.logger.Debug()
+		cfg.logger.Debug()
 	}
 
-	// Finally, set the exact pulse width
-	tinygopwm.SetDuty(h.pwm, h.channel, pulse, h.period)
-	h.pulse = pulse
+	// Check if the PWM resolution achieved for the period is enough for the chosen protocol
+	if cfg.protocol != ProtocolNil && cfg.MaxPulseWidth > cfg.MinPulseWidth &&
+		uint64(pwm.Top())*uint64(cfg.MaxPulseWidth-cfg.MinPulseWidth)/uint64(period) < uint64(MinProtocolResolution) {
+		return 0, ErrorCodeESCMotorUnsupportedProtocol
+	}
 
-	// Update the stop time if it is set to neutral
-	if pulse == h.neutralPulseWidth {
-		h.lastStopTime = time.Now()
+	// Get the channel from the pin
+	channel, err := pwm.Channel(pin)
+	if err != nil {
+		return 0, ErrorCodeESCMotorFailedToGetPWMChannel
 	}
+	return channel, tinygoerrors.ErrorCodeNil
 }
 
-// SetSpeed sets the ESC motor speed.
+// warnSuspiciousPulseWidths logs a warning for every pulse width setting that is valid but likely a typo, if the logger
+// is set
 //
 // Parameters:
 //
-// speed: Speed value between 0 (stop) and maxSpeed (full speed).
-// direction: Direction of the motor.
-//
-// Returns:
-//
-// An error if the speed could not be set, otherwise nil.
-func (h *DefaultHandler) SetSpeed(
-	speed float64,
-	direction Direction,
-) tinygoerrors.ErrorCode {
-	// Check if the is polarity inverted
-	if h.isPolarityInverted {
-		direction = direction.InvertedDirection()
+// cfg: The configuration of the ESC motor
+// period: The PWM period
+func warnSuspiciousPulseWidths(cfg *config, period uint32) {
+	if cfg.logger == nil {
+		return
 	}
 
-	// Check if the speed is within the valid range
-	if speed < 0 || speed > 1 {
-		return ErrorCodeESCMotorSpeedOutOfRange
+	// Check if the max pulse width leaves less low time than high time in the period
+	if cfg.MaxPulseWidth > period/2 {
+		cfg.logger.AddMessageWithUint32(
+			warnPulseNearPeriodPrefix,
+			cfg.MaxPulseWidth,
+			true,
+			true,
+			false,
+		)
+		cfg.logger.Warning()
 	}
 
-	// Calculate the pulse width based on the speed and direction
-	var pulse uint32
-	switch direction {
-	case DirectionStop:
-		speed = 0
-		pulse = h.neutralPulseWidth
-	case DirectionForward:
-		pulse = h.neutralPulseWidth + uint32(float64(h.maxPulseWidth-h.neutralPulseWidth)*speed)
-		h.speed = speed
-	case DirectionBackward:
-		pulse = h.neutralPulseWidth - uint32(float64(h.neutralPulseWidth-h.minPulseWidth)*speed)
-		h.speed = -speed
-	default:
-		return ErrorCodeESCMotorUnknownDirection
+	// Check if the standard pulse widths are outside the usual servo range, e.g. given in microseconds
+	if (cfg.protocol == ProtocolNil || cfg.protocol == ProtocolStandard) &&
+		(cfg.MinPulseWidth < TypicalMinPulseWidth || cfg.MaxPulseWidth > TypicalMaxPulseWidth) {
+		cfg.logger.AddMessageWithUint32(
+			warnPulseWidthRangePrefix,
+			cfg.MinPulseWidth,
+			true,
+			true,
+			false,
+		)
+		cfg.logger.Warning()
 	}
 
-	// Set the pulse width if movement is enabled
-	if h.isMovementEnabled != nil && !h.isMovementEnabled() {
-		pulse = h.neutralPulseWidth
-	} else if h.pulse != pulse {
-		// Check if it has to sleep the remaining time to match the interval delay
-		if !h.lastUpdate.IsZero() {
-			elapsed := time.Since(h.lastUpdate)
+	// Check if the neutral pulse width is centered between the min and max pulse widths, which the 3D mode requires
+	// exactly
+	if cfg.IsUnidirectional {
+		return
+	}
+	forwardSpan := cfg.MaxPulseWidth - cfg.NeutralPulseWidth
+	backwardSpan := cfg.NeutralPulseWidth - cfg.MinPulseWidth
+	tolerance := float64(cfg.MaxPulseWidth-cfg.MinPulseWidth) * NeutralOffCenterTolerance
+	if cfg.is3DMode && forwardSpan != backwardSpan {
+		cfg.logger.AddMessageWithUint32(
+			asymmetricMode3DPrefix,
+			cfg.NeutralPulseWidth,
+			true,
+			true,
+			false,
+		)
+		cfg.logger.Warning()
+	} else if !cfg.is3DMode && float64(pulseDistance(forwardSpan, backwardSpan)) > tolerance {
+		cfg.logger.AddMessageWithUint32(
+			warnNeutralOffCenterPrefix,
+			cfg.NeutralPulseWidth,
+			true,
+			true,
+			false,
+		)
+		cfg.logger.Warning()
+	}
+}
 
-			// Sleep the remaining time to match the period delay
-			if elapsed < h.periodDelay {
-				time.Sleep(h.periodDelay - elapsed)
-			}
-		}
+// validatePulseWidths checks if the pulse widths are valid for the given PWM period
+//
+// Parameters:
+//
+// minPulseWidth: Minimum pulse width for the ESC motor
+// neutralPulseWidth: Neutral pulse width for the ESC motor, equal to the min pulse width for unidirectional motors
+// maxPulseWidth: Maximum pulse width for the ESC motor
+// period: The PWM period
+// isUnidirectional: Whether the motor is unidirectional
+//
+// Returns:
+//
+// An error if any pulse width is invalid, otherwise nil
+func validatePulseWidths(
+	minPulseWidth uint32,
+	neutralPulseWidth uint32,
+	maxPulseWidth uint32,
+	period uint32,
+	isUnidirectional bool,
+) tinygoerrors.ErrorCode {
+	// Check if the motor is unidirectional, in which case there is no neutral between the min and max pulse widths
+	if isUnidirectional {
+		if minPulseWidth == 0 || minPulseWidth >= maxPulseWidth || minPulseWidth >= period {
+			return ErrorCodeESCMotorInvalidMinPulseWidth
+		}
+		if maxPulseWidth >= period {
+			return ErrorCodeESCMotorInvalidMaxPulseWidth
+		}
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Check if the neutral pulse width is within the valid range
+	if neutralPulseWidth < minPulseWidth || neutralPulseWidth > maxPulseWidth {
+		return ErrorCodeESCMotorInvalidNeutralPulseWidth
+	}
+
+	// Check if the min pulse width is valid
+	if minPulseWidth == 0 || minPulseWidth >= neutralPulseWidth || minPulseWidth >= period {
+		return ErrorCodeESCMotorInvalidMinPulseWidth
+	}
+
+	// Check if the max pulse width is valid
+	if maxPulseWidth == 0 || maxPulseWidth <= neutralPulseWidth || maxPulseWidth >= period {
+		return ErrorCodeESCMotorInvalidMaxPulseWidth
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// writePulseWidth writes the pulse width to the PWM channel and updates the pulse bookkeeping
+//
+// Parameters:
+//
+// pulse: The pulse width value to write
+//
+// Returns:
+//
+// An error if the duty cycle could not be set, in which case the pulse bookkeeping is left unchanged, otherwise nil
+func (h *DefaultHandler) writePulseWidth(pulse uint32) tinygoerrors.ErrorCode {
+	// Check if the duty cycle can be set
+	if h.period == 0 {
+		return ErrorCodeESCMotorFailedToSetDuty
+	}
+	if err := h.writeDuty(pulse); err != nil {
+		return ErrorCodeESCMotorFailedToSetDuty
+	}
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	previous := h.pulse
+	h.pulse = pulse
+	h.isPulseWritten = true
+
+	// Update the peak pulse if it is further from neutral than the previous one
+	if pulseDistance(pulse, h.neutralPulseWidth) > pulseDistance(h.peakPulse, h.neutralPulseWidth) {
+		h.peakPulse = pulse
+	}
+
+	// Update the stop time if it is set to or crosses neutral
+	if pulse == h.neutralPulseWidth || (previous < h.neutralPulseWidth && pulse > h.neutralPulseWidth) ||
+		(previous > h.neutralPulseWidth && pulse < h.neutralPulseWidth) {
+		h.lastStopTime = h.clock.Now()
+	}
+
+	// Record the pulse width if recording
+	if h.isRecording {
+		h.recordedSamples = append(h.recordedSamples, PulseSample{Elapsed: h.since(h.recordStart), Pulse: pulse})
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// rewritePulseWidth writes the current pulse width again, e.g. after the output configuration changed, unless no pulse
+// width has been written yet, in which case the output stays untouched
+//
+// Returns:
+//
+// An error if the duty cycle could not be set, otherwise nil
+func (h *DefaultHandler) rewritePulseWidth() tinygoerrors.ErrorCode {
+	if !h.isPulseWritten {
+		return tinygoerrors.ErrorCodeNil
+	}
+	return h.writePulseWidth(h.pulse)
+}
+
+// writePWMDuty sets the duty cycle of the PWM channel for the pulse width, the default duty writer
+//
+// Parameters:
+//
+// pulse: The pulse width value to write
+//
+// Returns:
+//
+// An error if the PWM is nil, which tinygopwm.SetDuty would silently ignore, or the pulse width does not fit inside the
+// period, otherwise nil
+func (h *DefaultHandler) writePWMDuty(pulse uint32) error {
+	if h.pwm == nil {
+		return errNilPWM
+	}
+
+	// Check if the pulse width fits inside the period, the inverted duty cycle would wrap around otherwise
+	if pulse > h.period {
+		return errPulseOutOfPeriod
+	}
+
+	// Check if the signal is inverted, in which case the low time of the period carries the pulse
+	duty := pulse
+	if h.isSignalInverted {
+		duty = h.period - pulse
+	}
+
+	// Write the same duty cycle to every channel driven by the handler
+	tinygopwm.SetDuty(h.pwm, h.channel, duty, h.period)
+	for _, channel := range h.extraChannels {
+		tinygopwm.SetDuty(h.pwm, channel, duty, h.period)
+	}
+	return nil
+}
+
+// recordDuty records the pulse width into the pulse history, the duty writer of the dry runs
+//
+// Parameters:
+//
+// pulse: The pulse width value to record
+//
+// Returns:
+//
+// Always nil
+func (h *DefaultHandler) recordDuty(pulse uint32) error {
+	h.stateMutex.Lock()
+	h.pulseHistory = append(h.pulseHistory, pulse)
+	h.stateMutex.Unlock()
+	return nil
+}
+
+// writeRampPulseWidth writes a pulse width of a ramp, clamped into the min and max pulse widths, and calls the pulse
+// step function
+//
+// Parameters:
+//
+// pulse: The pulse width value to write
+//
+// Returns:
+//
+// An error if the duty cycle could not be set, otherwise nil
+func (h *DefaultHandler) writeRampPulseWidth(pulse uint32) tinygoerrors.ErrorCode {
+	// Clamp the pulse width into the configured bounds as a final safety net against the mapping math
+	if clamped := clampPulseWidth(pulse, h.minPulseWidth, h.maxPulseWidth); clamped != pulse {
+		if h.logger != nil {
+			h.logger.AddMessageWithUint32(
+				clampPulseWidthPrefix,
+				pulse,
+				true,
+				true,
+				false,
+			)
+			h.logger.Warning()
+		}
+		pulse = clamped
+	}
+	if errCode := h.writePulseWidth(pulse); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Call the pulse step function if provided
+	if h.onPulseStep != nil {
+		h.onPulseStep(pulse)
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// dwellOnZeroCross writes the neutral pulse width and dwells on it if a ramp step lands on or crosses neutral
+//
+// Parameters:
+//
+// from: The pulse width before the step
+// to: The pulse width after the step
+// target: The target pulse width of the ramp
+//
+// Returns:
+//
+// An error if the neutral pulse width could not be written, otherwise nil
+func (h *DefaultHandler) dwellOnZeroCross(from, to, target uint32) tinygoerrors.ErrorCode {
+	// Check if the dwell is enabled and the ramp passes through neutral instead of ending on it
+	if h.zeroCrossDwell <= 0 || from == h.neutralPulseWidth || target == h.neutralPulseWidth {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Check if the step lands on or crosses neutral
+	if (from < h.neutralPulseWidth && to < h.neutralPulseWidth) ||
+		(from > h.neutralPulseWidth && to > h.neutralPulseWidth) {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Let the ESC register the stop
+	if errCode := h.writeRampPulseWidth(h.neutralPulseWidth); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+	h.sleep(h.zeroCrossDwell)
+	return tinygoerrors.ErrorCodeNil
+}
+
+// scaleDelay scales a direction-change delay by the configured delay scale
+//
+// Parameters:
+//
+// delay: The base delay to scale
+//
+// Returns:
+//
+// The scaled delay
+func (h *DefaultHandler) scaleDelay(delay time.Duration) time.Duration {
+	return time.Duration(float64(delay) * h.delayScale)
+}
+
+// applyThrottleCurve applies the configured throttle curve to the speed, clamping the output to [0, 1]
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed)
+//
+// Returns:
+//
+// The curved speed
+func (h *DefaultHandler) applyThrottleCurve(speed float64) float64 {
+	var curved float64
+	if h.throttleCurve == ThrottleCurveCustom {
+		if h.customThrottleCurve == nil {
+			return speed
+		}
+		curved = h.customThrottleCurve(speed)
+	} else {
+		curved = h.throttleCurve.Apply(speed)
+	}
+
+	// Clamp the curved speed
+	if curved < 0 {
+		return 0
+	}
+	if curved > 1 {
+		return 1
+	}
+	return curved
+}
+
+// speedToPulse maps a speed onto the pulse width for the given direction
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed)
+// direction: Physical direction of the motor, either forward or backward
+//
+// Returns:
+//
+// The pulse width for the speed and direction
+func (h *DefaultHandler) speedToPulse(speed float64, direction Direction) uint32 {
+	// Check if the speed is zero, in which case the start offset does not apply
+	if speed == 0 {
+		return h.neutralPulseWidth
+	}
+
+	// Apply the throttle curve
+	speed = h.applyThrottleCurve(speed)
+
+	// Check if the throttle sides are swapped, in which case the direction maps onto the opposite half of the pulse
+	// widths, the start offsets follow the half
+	if h.isThrottleSideSwapped {
+		direction = direction.InvertedDirection()
+	}
+
+	// Map the speed onto the span after the start offset, capping the offset to the span so the floating point rounding
+	// near full speed never overshoots the max pulse width nor underflows below the min pulse width
+	if direction == DirectionForward {
+		start := h.neutralPulseWidth + h.forwardStartOffset
+		return start + spanOffset(h.maxPulseWidth-start, speed)
+	}
+	start := h.neutralPulseWidth - h.backwardStartOffset
+	return start - spanOffset(start-h.minPulseWidth, speed)
+}
+
+// isRampCancelled checks if the in-flight async ramp has been cancelled
+//
+// Returns:
+//
+// True if the ramp has been cancelled, otherwise false
+func (h *DefaultHandler) isRampCancelled() bool {
+	select {
+	case <-h.rampCancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep sleeps the given duration, waking up early if the in-flight async ramp is cancelled
+//
+// Parameters:
+//
+// duration: The duration to sleep
+//
+// Returns:
+//
+// False if the ramp has been cancelled, otherwise true
+func (h *DefaultHandler) sleep(duration time.Duration) bool {
+	if duration <= 0 {
+		return !h.isRampCancelled()
+	}
+
+	// Check if there is an async ramp that could be cancelled
+	if h.rampCancel == nil {
+		h.clock.Sleep(duration)
+		return true
+	}
+
+	select {
+	case <-h.rampCancel:
+		return false
+	case <-h.clock.After(duration):
+		return true
+	}
+}
+
+// rampPulseStep returns the pulse width step to ramp between two pulse widths. The duration of a ramp-to-stop takes
+// precedence over everything else, the ramp duration takes precedence over the per-direction pulse steps, which take
+// precedence over the pulse step, a soft start launch only ever makes the step smaller and the maximum ramp time only
+// ever makes it larger
+//
+// Parameters:
+//
+// from: The pulse width at the start of the ramp
+// to: The target pulse width of the ramp
+// softStartDuration: The duration of the soft start limiting the ramp, zero if it does not apply
+//
+// Returns:
+//
+// The pulse width step, zero if the pulse width jumps to the target at once
+func (h *DefaultHandler) rampPulseStep(from, to uint32, softStartDuration time.Duration) uint32 {
+	distance := pulseDistance(from, to)
+	if distance == 0 {
+		return 0
+	}
+
+	// Check if a ramp-to-stop is running, in which case its duration takes precedence over everything else
+	if h.isStopRamp {
+		if steps := h.periodsIn(h.stopRampDuration); steps != 0 {
+			return divideRoundingUp(distance, steps)
+		}
+		return 0
+	}
+
+	// Check if the step is computed to complete the ramp in the configured duration
+	var step uint32
+	if h.rampDuration > 0 {
+		if steps := h.periodsIn(h.rampDuration); steps != 0 {
+			step = divideRoundingUp(distance, steps)
+		}
+	} else if pulseStep := h.directionPulseStep(from, to); pulseStep != nil {
+		step = *pulseStep
+	}
+
+	// Check if the soft start limits the ramp further
+	if softStartDuration > 0 {
+		if steps := h.periodsIn(softStartDuration); steps != 0 {
+			if softStartStep := divideRoundingUp(distance, steps); step == 0 || softStartStep < step {
+				step = softStartStep
+			}
+		}
+	}
+
+	// Check if the ramp would take longer than the maximum ramp time, in which case the step is enlarged to fit in it
+	if periods := h.periodsIn(h.maxRampTime); h.maxRampTime > 0 && step != 0 && periods < math.MaxUint32 {
+		if maxSteps := periods + 1; divideRoundingUp(distance, step) > maxSteps {
+			step = divideRoundingUp(distance, maxSteps)
+		}
+	}
+	return step
+}
+
+// directionPulseStep returns the pulse step for the side of neutral a ramp moves on, the side of the target or, when
+// ramping to neutral, the side it starts from
+//
+// Parameters:
+//
+// from: The pulse width at the start of the ramp
+// to: The target pulse width of the ramp
+//
+// Returns:
+//
+// The pulse step of the side, the pulse step if it is not set, nil if neither is set
+func (h *DefaultHandler) directionPulseStep(from, to uint32) *uint32 {
+	side := to
+	if side == h.neutralPulseWidth {
+		side = from
+	}
+
+	// Check if the direction of the side has its own pulse step
+	direction := h.pulseDirection(side)
+	if direction == DirectionForward && h.forwardPulseStep != nil {
+		return h.forwardPulseStep
+	}
+	if direction == DirectionBackward && h.backwardPulseStep != nil {
+		return h.backwardPulseStep
+	}
+	return h.pulseStep
+}
+
+// rampSteps returns the number of writes to ramp between two pulse widths, including the final one
+//
+// Parameters:
+//
+// from: The pulse width at the start of the ramp
+// to: The target pulse width of the ramp
+// softStartDuration: The duration of the soft start limiting the ramp, zero if it does not apply
+//
+// Returns:
+//
+// The number of writes, zero if there is nothing to ramp and one if the pulse width jumps to the target at once
+func (h *DefaultHandler) rampSteps(from, to uint32, softStartDuration time.Duration) uint32 {
+	distance := pulseDistance(from, to)
+	if distance == 0 {
+		return 0
+	}
+
+	// Check if the pulse width jumps to the target at once
+	step := h.rampPulseStep(from, to, softStartDuration)
+	if step == 0 {
+		return 1
+	}
+	return divideRoundingUp(distance, step)
+}
+
+// periodsIn returns how many whole PWM periods fit in a duration, saturating at the uint32 bounds instead of wrapping
+// around
+//
+// Parameters:
+//
+// duration: The duration to split into periods
+//
+// Returns:
+//
+// The number of whole periods
+func (h *DefaultHandler) periodsIn(duration time.Duration) uint32 {
+	if duration <= 0 || h.periodDelay <= 0 {
+		return 0
+	}
+	periods := duration / h.periodDelay
+	if periods > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(periods)
+}
+
+// graduallySetPulseWidth gradually sets the pulse width to the pulse value, returning early if the async ramp is
+// cancelled
+//
+// Parameters:
+//
+// pulse: The pulse pulse width value to set
+//
+// Returns:
+//
+// An error if a step could not be written, in which case the ramp is aborted and the pulse reflects the last
+// successful write, otherwise nil
+func (h *DefaultHandler) graduallySetPulseWidth(pulse uint32) tinygoerrors.ErrorCode {
+	from := h.pulse
+	defer h.recordRampDuration(h.clock.Now(), from != pulse)
+
+	// Split the distance into evenly spaced steps no larger than the ramp step, so the last step does not jump the
+	// remainder at once
+	steps := h.rampSteps(from, pulse, h.softStartDuration)
+	h.stateMutex.Lock()
+	h.rampTarget = pulse
+	h.rampStep = 0
+	if steps > 1 {
+		h.rampStep = divideRoundingUp(pulseDistance(from, pulse), steps)
+	}
+	h.stateMutex.Unlock()
+
+	// Gradually increment or decrement the pulse through the intermediate steps, interpolating in 64 bits so the steps
+	// never wrap around near the uint32 bounds
+	for i := uint32(1); i < steps; i++ {
+		errCode := h.writeRampStep(interpolatePulseWidth(from, pulse, i, steps), pulse)
+		if errCode != tinygoerrors.ErrorCodeNil {
+			return errCode
+		}
+		if !h.sleep(h.periodDelay) {
+			return tinygoerrors.ErrorCodeNil
+		}
+
+		// Check if the movement got disabled mid-ramp, in which case the motor is stopped immediately
+		if pulse != h.neutralPulseWidth && pulse != h.stopPulseWidth() && h.isMovementDisabled() {
+			return h.abortToStop()
+		}
+	}
+
+	// Dwell at neutral if the last step lands on or crosses it
+	if errCode := h.dwellOnZeroCross(h.pulse, pulse, pulse); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+	if h.isRampCancelled() {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Log the final pulse, unless the logs are deferred until the ramp completes
+	if !h.isDeferredLogging && h.isLogEnabled(LogCategoryPulseStep) {
+		h.logger.AddMessageWithUint32(
+			setPulseWidthPrefix,
+			pulse,
+			true,
+			true,
+			false,
+		)
+		h.log(LogCategoryPulseStep)
+	}
+
+	// Finally, set the exact pulse width
+	errCode := h.writeRampPulseWidth(pulse)
+
+	// Log the summary of the ramp once it completes
+	if errCode == tinygoerrors.ErrorCodeNil && h.isDeferredLogging && h.isLogEnabled(LogCategoryPulseStep) {
+		h.logRampSummary(from, pulse, steps)
+	}
+	return errCode
+}
+
+// logRampSummary logs the start and target pulse widths and the number of steps of a completed ramp
+//
+// Parameters:
+//
+// from: The pulse width the ramp started from
+// to: The target pulse width of the ramp
+// steps: The number of steps of the ramp
+func (h *DefaultHandler) logRampSummary(from, to, steps uint32) {
+	h.logger.AddMessageWithUint32(
+		rampFromPrefix,
+		from,
+		true,
+		false,
+		false,
+	)
+	h.logger.AddMessageWithUint32(
+		rampToPrefix,
+		to,
+		true,
+		false,
+		false,
+	)
+	h.logger.AddMessageWithUint32(
+		rampStepsPrefix,
+		steps,
+		true,
+		true,
+		false,
+	)
+	h.log(LogCategoryPulseStep)
+}
+
+// abortToStop drives the stop pulse width at once, aborting the ramp in progress and recording the motor as stopped
+//
+// Returns:
+//
+// An error if the stop pulse width could not be written, otherwise ErrorCodeESCMotorMovementDisabled
+func (h *DefaultHandler) abortToStop() tinygoerrors.ErrorCode {
+	if errCode := h.writePulseWidth(h.stopPulseWidth()); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.stateMutex.Unlock()
+	h.completeDirection(DirectionStop)
+	return ErrorCodeESCMotorMovementDisabled
+}
+
+// getClock returns the source of time of the handler, shared with the wrappers that measure time
+//
+// Returns:
+//
+// The clock of the handler
+func (h *DefaultHandler) getClock() clock {
+	return h.clock
+}
+
+// since returns the time elapsed since a time on the clock of the handler
+//
+// Parameters:
+//
+// t: The time to measure from
+//
+// Returns:
+//
+// The elapsed time
+func (h *DefaultHandler) since(t time.Time) time.Duration {
+	return h.clock.Now().Sub(t)
+}
+
+// recordRampDuration records the duration of the ramp that started at the given time
+//
+// Parameters:
+//
+// start: The time the ramp started
+// isMoved: Whether the ramp moved the pulse width, only those are counted
+func (h *DefaultHandler) recordRampDuration(start time.Time, isMoved bool) {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.lastRampDuration = h.since(start)
+	if isMoved {
+		h.rampCount++
+		h.totalRampDuration += h.lastRampDuration
+	}
+}
+
+// writeRampStep writes an intermediate step of a ramp
+//
+// Parameters:
+//
+// step: The pulse width of the step
+// target: The target pulse width of the ramp
+//
+// Returns:
+//
+// An error if the step could not be written, otherwise nil
+func (h *DefaultHandler) writeRampStep(step, target uint32) tinygoerrors.ErrorCode {
+	// Dwell at neutral if the step lands on or crosses it
+	if errCode := h.dwellOnZeroCross(h.pulse, step, target); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Log the gradual step if the intermediate steps are logged while stepping
+	if h.isVerbosePulseLogging && !h.isDeferredLogging && h.isLogEnabled(LogCategoryPulseStep) {
+		h.logger.AddMessageWithUint32(
+			setPulseWidthPrefix,
+			step,
+			true,
+			true,
+			false,
+		)
+		h.log(LogCategoryPulseStep)
+	}
+	return h.writeRampPulseWidth(step)
+}
+
+// isLogEnabled checks if the log messages of a category are logged
+//
+// Parameters:
+//
+// category: The category of the log messages
+//
+// Returns:
+//
+// True if the logger is set and the category is not suppressed, otherwise false
+func (h *DefaultHandler) isLogEnabled(category LogCategory) bool {
+	return h.logger != nil && h.logLevels[category] != LogLevelNone
+}
+
+// log logs the added log message with the logger method of its category
+//
+// Parameters:
+//
+// category: The category of the log message
+func (h *DefaultHandler) log(category LogCategory) {
+	switch h.logLevels[category] {
+	case LogLevelInfo:
+		h.logger.Info()
+	case LogLevelWarning:
+		h.logger.Warning()
+	case LogLevelError:
+		h.logger.Error()
+	default:
+		h.logger.Debug()
+	}
+}
+
+// reportError calls the error callback if the operation failed
+//
+// Parameters:
+//
+// errCode: The error code returned by the operation
+// op: The label of the operation
+//
+// Returns:
+//
+// The same error code
+func (h *DefaultHandler) reportError(errCode tinygoerrors.ErrorCode, op string) tinygoerrors.ErrorCode {
+	if errCode == tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Emit the fault and call the error callback
+	h.emitEvent(EventTypeFault, errCode)
+	h.stateMutex.RLock()
+	onError := h.onError
+	h.stateMutex.RUnlock()
+	if onError != nil {
+		onError(errCode, op)
+	}
+	return errCode
+}
+
+// emitEvent sends a state-change event without blocking, dropping it if the events channel is full
+//
+// Parameters:
+//
+// eventType: The type of the event
+// errCode: The error code of the event, nil if it is not a fault
+func (h *DefaultHandler) emitEvent(eventType EventType, errCode tinygoerrors.ErrorCode) {
+	h.stateMutex.RLock()
+	event := Event{
+		Type:      eventType,
+		Speed:     h.speed,
+		Direction: h.direction,
+		Pulse:     h.pulse,
+		ErrorCode: errCode,
+	}
+	h.stateMutex.RUnlock()
+
+	select {
+	case h.events <- event:
+	default:
+	}
+}
+
+// SetSpeed sets the ESC motor speed.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeed(
+	speed float64,
+	direction Direction,
+) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+	return h.reportError(h.setSpeed(speed, direction), OpSetSpeed)
+}
+
+// limitBoost clamps a forward speed above the boost threshold to it unless the boost is enabled. The caller must hold
+// the state mutex.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed)
+// direction: Direction of the motor, as commanded
+//
+// Returns:
+//
+// The speed, clamped to the boost threshold, and ErrorCodeESCMotorBoostNotEnabled if it was clamped, otherwise nil
+func (h *DefaultHandler) limitBoost(speed float64, direction Direction) (float64, tinygoerrors.ErrorCode) {
+	if h.boostThreshold <= 0 || direction != DirectionForward || speed <= h.boostThreshold || h.isBoostEnabled {
+		return speed, tinygoerrors.ErrorCodeNil
+	}
+	return h.boostThreshold, ErrorCodeESCMotorBoostNotEnabled
+}
+
+// EnableBoost allows the forward speeds above the boost threshold set by WithBoostThreshold.
+func (h *DefaultHandler) EnableBoost() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.isBoostEnabled = true
+}
+
+// DisableBoost clamps the forward speeds above the boost threshold set by WithBoostThreshold again. The current speed
+// is left unchanged until the next command.
+func (h *DefaultHandler) DisableBoost() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.isBoostEnabled = false
+}
+
+// IsBoostEnabled returns whether the forward speeds above the boost threshold are allowed.
+//
+// Returns:
+//
+// True if the boost is enabled, otherwise false
+func (h *DefaultHandler) IsBoostEnabled() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isBoostEnabled
+}
+
+// resolveSpeedCommand checks a speed command and resolves it into the physical direction and pulse width to drive,
+// updating the input smoothing, the thermal derating and the current limit protection and feeding the failsafe
+// watchdog
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// The resolved speed command and an error if the command cannot be driven, otherwise nil.
+func (h *DefaultHandler) resolveSpeedCommand(
+	speed float64,
+	direction Direction,
+) (speedCommand, tinygoerrors.ErrorCode) {
+	// Check if the command is valid before reading the sensors
+	if errCode := h.checkSpeedCommand(speed, direction); errCode != tinygoerrors.ErrorCodeNil {
+		return speedCommand{}, errCode
+	}
+
+	// Check if the thermal derating or the current limit protection cap the speed
+	ceiling := h.updateThermalCeiling()
+	if h.updateCurrentLimit() && h.currentCeiling < ceiling {
+		ceiling = h.currentCeiling
+	}
+
+	// Resolve the command and store the filtered speed of the input smoothing
+	h.stateMutex.Lock()
+	cmd, errCode := h.evaluateSpeedCommand(speed, direction, ceiling)
+	if errCode == tinygoerrors.ErrorCodeNil {
+		h.filteredSpeed = cmd.filteredSpeed
+	}
+	h.stateMutex.Unlock()
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return speedCommand{}, errCode
+	}
+
+	// Feed the failsafe watchdog, only once the command is valid
+	h.Feed()
+	return cmd, tinygoerrors.ErrorCodeNil
+}
+
+// checkSpeedCommand checks if a speed command is valid, regardless of the state of the motor
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the handler has been closed, the direction is unknown or the speed is out of range, otherwise nil
+func (h *DefaultHandler) checkSpeedCommand(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Check if the direction is valid, DirectionNil is easy to pass by accident as the zero value
+	if !direction.IsValid() {
+		return ErrorCodeESCMotorUnknownDirection
+	}
+
+	// Check if the speed is within the valid range, NaN fails every comparison so it is checked on its own
+	if math.IsNaN(speed) || speed < 0 || speed > 1 {
+		return ErrorCodeESCMotorSpeedOutOfRange
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// evaluateSpeedCommand resolves a valid speed command into the physical direction and pulse width to drive without
+// side effects, so it can also be used to estimate a command. The caller must hold the state mutex.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+// ceiling: The speed ceiling of the thermal derating and the current limit protection
+//
+// Returns:
+//
+// The resolved speed command and an error if the command cannot be driven, otherwise nil.
+func (h *DefaultHandler) evaluateSpeedCommand(
+	speed float64,
+	direction Direction,
+	ceiling float64,
+) (speedCommand, tinygoerrors.ErrorCode) {
+	// Check if the speed is above the boost threshold, in which case it is clamped unless the boost is enabled
+	speed, boostErrCode := h.limitBoost(speed, direction)
+
+	// Check if the is polarity inverted
+	if h.isPolarityInverted {
+		direction = direction.InvertedDirection()
+	}
+
+	// Check if the input smoothing filters the speed
+	cmd := speedCommand{errCode: boostErrCode}
+	if h.inputSmoothing > 0 {
+		cmd.filteredSpeed = h.filterSpeed(speed, direction)
+		if direction == DirectionForward || direction == DirectionBackward {
+			speed, direction = splitSignedSpeed(cmd.filteredSpeed)
+		}
+	}
+
+	// Check if the thermal derating or the current limit protection cap the speed
+	if speed > ceiling {
+		speed = ceiling
+	}
+
+	// Check if the speed falls inside the deadband, in which case the motor is stopped
+	if speed < h.deadband && (direction == DirectionForward || direction == DirectionBackward) {
+		direction = DirectionStop
+	}
+
+	// Check if the speed is below the min effective speed, in which case it is bumped up to it so the motor is either
+	// stopped or turning, never buzzing, while the ceilings still apply
+	if speed > 0 && speed < h.minEffectiveSpeed && (direction == DirectionForward || direction == DirectionBackward) {
+		speed = math.Min(h.minEffectiveSpeed, ceiling)
+	}
+
+	// Check if the neutral lock is engaged, in which case neutral is driven regardless of the command
+	if h.isNeutralLocked && (direction == DirectionForward || direction == DirectionBackward) {
+		direction = DirectionStop
+		cmd.errCode = ErrorCodeESCMotorNeutralLocked
+	}
+
+	// Check if the motor must be armed before moving
+	if h.isArmRequired && !h.isArmed && (direction == DirectionForward || direction == DirectionBackward) {
+		return speedCommand{}, ErrorCodeESCMotorNotArmed
+	}
+
+	// Calculate the pulse width based on the speed and direction
+	switch direction {
+	case DirectionStop:
+		speed = 0
+		cmd.pulse = h.stopPulseWidth()
+		if cmd.errCode == ErrorCodeESCMotorNeutralLocked {
+			cmd.pulse = h.neutralPulseWidth
+		}
+	case DirectionForward:
+		cmd.pulse = h.speedToPulse(speed, direction)
+		cmd.signedSpeed = speed
+	case DirectionBackward:
+		if h.isUnidirectional {
+			return speedCommand{}, ErrorCodeESCMotorBackwardNotSupported
+		}
+		cmd.pulse = h.speedToPulse(speed, direction)
+		cmd.signedSpeed = -speed
+
+		// Check if the backward command brakes the forward motion instead of reversing, the ESC only reverses after
+		// a stop command
+		if h.isBrakeMode && (h.direction == DirectionForward || h.direction == DirectionBrake) {
+			direction = DirectionBrake
+			cmd.signedSpeed = 0
+		}
+	default:
+		return speedCommand{}, ErrorCodeESCMotorUnknownDirection
+	}
+	cmd.speed = speed
+	cmd.direction = direction
+	return cmd, tinygoerrors.ErrorCodeNil
+}
+
+// filterSpeed blends a forward or backward speed into the exponential moving average of the input smoothing without
+// storing it, any other direction resets the filter
+//
+// Parameters:
+//
+// speed: Speed value between 0 and 1
+// direction: Physical direction of the motor
+//
+// Returns:
+//
+// The new filtered speed, negative backward
+func (h *DefaultHandler) filterSpeed(speed float64, direction Direction) float64 {
+	// Check if the direction moves the motor, otherwise the filter forgets the previous speeds
+	switch direction {
+	case DirectionForward:
+	case DirectionBackward:
+		speed = -speed
+	default:
+		return 0
+	}
+	return h.inputSmoothing*speed + (1-h.inputSmoothing)*h.filteredSpeed
+}
+
+// splitSignedSpeed splits a filtered speed into its magnitude and physical direction
+//
+// Parameters:
+//
+// filteredSpeed: The filtered speed, negative backward
+//
+// Returns:
+//
+// The speed magnitude and its direction, DirectionStop if it is zero
+func splitSignedSpeed(filteredSpeed float64) (float64, Direction) {
+	if filteredSpeed < 0 {
+		return -filteredSpeed, DirectionBackward
+	}
+	if filteredSpeed > 0 {
+		return filteredSpeed, DirectionForward
+	}
+	return 0, DirectionStop
+}
+
+// stopPulseWidth returns the pulse width driven when the motor is stopped
+//
+// Returns:
+//
+// The min pulse width if the stop pulse is StopPulseMin, otherwise the neutral pulse width
+func (h *DefaultHandler) stopPulseWidth() uint32 {
+	if h.stopPulse == StopPulseMin {
+		return h.minPulseWidth
+	}
+	return h.neutralPulseWidth
+}
+
+// pulseDirection returns the physical direction a pulse width drives, taking the swapped throttle sides into account
+//
+// Parameters:
+//
+// pulse: The pulse width
+//
+// Returns:
+//
+// The physical direction, DirectionStop at neutral
+func (h *DefaultHandler) pulseDirection(pulse uint32) Direction {
+	direction := DirectionStop
+	if pulse > h.neutralPulseWidth {
+		direction = DirectionForward
+	} else if pulse < h.neutralPulseWidth {
+		direction = DirectionBackward
+	}
+	if h.isThrottleSideSwapped {
+		return direction.InvertedDirection()
+	}
+	return direction
+}
+
+// applySpeedCommand stores the speed of a resolved speed command before driving it
+//
+// Parameters:
+//
+// cmd: The resolved speed command
+func (h *DefaultHandler) applySpeedCommand(cmd speedCommand) {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.speed = cmd.signedSpeed
+	h.isManualOverride = false
+
+	// Update the peak speed
+	if cmd.speed > h.peakSpeed {
+		h.peakSpeed = cmd.speed
+	}
+}
+
+// isNeutralDetourRequired checks if the pulse width has to go back to neutral before driving the direction
+//
+// Parameters:
+//
+// direction: The physical direction to drive
+//
+// Returns:
+//
+// True if the direction changes and the pulse width has to pass through neutral first, otherwise false
+func (h *DefaultHandler) isNeutralDetourRequired(direction Direction) bool {
+	// Braking drives below neutral without passing through it first, a coasting motor is already at neutral and the
+	// instant reversals sweep straight through it
+	return !h.isReversalInstant() && (h.direction != direction) && (h.direction != DirectionStop) &&
+		(h.direction != DirectionCoast) && (direction != DirectionBrake)
+}
+
+// isReversalInstant checks if the direction reversals sweep straight through neutral without the direction-change
+// delays, either because the ESC runs in 3D mode or the direct inversion is enabled
+//
+// Returns:
+//
+// True if the reversals are instant, otherwise false
+func (h *DefaultHandler) isReversalInstant() bool {
+	return h.is3DMode || h.isDirectInvert
+}
+
+// directionChangeDelay returns the remaining delay to wait before driving the direction
+//
+// Parameters:
+//
+// direction: The physical direction to drive
+//
+// Returns:
+//
+// The remaining delay, clamped to zero if there is nothing left to wait
+func (h *DefaultHandler) directionChangeDelay(direction Direction) time.Duration {
+	// Check if the reversals are instant, in which case there is nothing to wait
+	if h.isReversalInstant() {
+		return 0
+	}
+
+	var delay time.Duration
+	if h.direction != DirectionForward && h.direction != DirectionBrake && direction == DirectionForward {
+		delay = h.scaleDelay(h.backwardToForwardDelay)
+	} else if h.direction != DirectionBackward && direction == DirectionBackward {
+		delay = h.scaleDelay(h.forwardToBackwardDelay)
+	} else {
+		return 0
+	}
+
+	// Discount the time already spent at neutral, if the motor has not stopped yet the whole delay remains
+	if !h.lastStopTime.IsZero() {
+		delay -= h.since(h.lastStopTime)
+	}
+
+	// Check if the delay has already elapsed
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// completeDirection updates the current direction once the pulse width is on the side of the driven direction
+//
+// Parameters:
+//
+// direction: The physical direction driven
+//
+// Returns:
+//
+// True if the direction has changed, otherwise false
+func (h *DefaultHandler) completeDirection(direction Direction) bool {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	isDirectionChanged := h.direction != direction
+	h.direction = direction
+	if direction != DirectionStop && direction != DirectionCoast {
+		// Reset the last stop time if not stopping
+		h.lastStopTime = time.Time{}
+	}
+	return isDirectionChanged
+}
+
+// completeRamp records the end of a ramp and emits the state-change events
+//
+// Parameters:
+//
+// isDirectionChanged: Whether the ramp changed the direction
+func (h *DefaultHandler) completeRamp(isDirectionChanged bool) {
+	// Set the last update time
+	h.stateMutex.Lock()
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+
+	// Emit the state-change events
+	h.emitEvent(EventTypeRampComplete, tinygoerrors.ErrorCodeNil)
+	if isDirectionChanged {
+		h.emitEvent(EventTypeDirectionChanged, tinygoerrors.ErrorCodeNil)
+	}
+	h.emitEvent(EventTypeSpeedChanged, tinygoerrors.ErrorCodeNil)
+}
+
+// finishSpeedCommand logs the driven speed command and calls the after set speed function
+//
+// Parameters:
+//
+// cmd: The driven speed command
+func (h *DefaultHandler) finishSpeedCommand(cmd speedCommand) {
+	// Log the speed change
+	if h.logger != nil {
+		switch cmd.direction {
+		case DirectionStop:
+			if h.isLogEnabled(LogCategoryStop) {
+				h.logger.AddMessage(
+					stopPrefix,
+					true,
+				)
+				h.log(LogCategoryStop)
+			}
+		case DirectionForward:
+			if h.isLogEnabled(LogCategorySpeed) {
+				h.logger.AddMessageWithFloat64(
+					setSpeedForwardPrefix,
+					cmd.speed,
+					h.floatPrecision,
+					true,
+					true,
+				)
+				h.log(LogCategorySpeed)
+			}
+		case DirectionBackward:
+			if h.isLogEnabled(LogCategorySpeed) {
+				h.logger.AddMessageWithFloat64(
+					setSpeedBackwardPrefix,
+					cmd.speed,
+					h.floatPrecision,
+					true,
+					true,
+				)
+				h.log(LogCategorySpeed)
+			}
+		case DirectionBrake:
+			if h.isLogEnabled(LogCategorySpeed) {
+				h.logger.AddMessageWithFloat64(
+					brakePrefix,
+					cmd.speed,
+					h.floatPrecision,
+					true,
+					true,
+				)
+				h.log(LogCategorySpeed)
+			}
+		}
+	}
+
+	// Call the after set speed function if provided
+	if h.afterSetSpeedFunc != nil {
+		h.afterSetSpeedFunc(h.speed)
+	}
+}
+
+// isMovementDisabled checks if the movement enable function reports the movement as disabled, caching the result
+//
+// Returns:
+//
+// True if the movement is disabled, otherwise false
+func (h *DefaultHandler) isMovementDisabled() bool {
+	isDisabled := h.isMovementEnabled != nil && !h.isMovementEnabled()
+	h.stateMutex.Lock()
+	h.isMovementObserved = !isDisabled
+	h.stateMutex.Unlock()
+	return isDisabled
+}
+
+// GetMovementEnabled returns whether the movement was enabled the last time the handler evaluated the movement enabled
+// function, without calling it again.
+//
+// Returns:
+//
+// True if the movement was enabled, or if no movement enabled function is set, otherwise false
+func (h *DefaultHandler) GetMovementEnabled() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isMovementObserved
+}
+
+// RefreshMovementEnabled evaluates the movement enabled function again and caches the result.
+//
+// Returns:
+//
+// True if the movement is enabled, or if no movement enabled function is set, otherwise false
+func (h *DefaultHandler) RefreshMovementEnabled() bool {
+	return !h.isMovementDisabled()
+}
+
+// setSpeed sets the ESC motor speed without reporting the error
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) setSpeed(
+	speed float64,
+	direction Direction,
+) tinygoerrors.ErrorCode {
+	cmd, errCode := h.resolveSpeedCommand(speed, direction)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+	h.applySpeedCommand(cmd)
+
+	// Set the pulse width if movement is enabled
+	if h.isMovementDisabled() {
+		cmd.pulse = h.stopPulseWidth()
+
+		// Check if no pulse width has been written yet, e.g. the initial stop was skipped, in which case the stop pulse
+		// width is written so the output is known
+		if !h.isPulseWritten {
+			if errCode = h.writePulseWidth(cmd.pulse); errCode != tinygoerrors.ErrorCodeNil {
+				return errCode
+			}
+		}
+	} else if h.pulse != cmd.pulse || !h.isPulseWritten {
+		// Check if it has to sleep the remaining time to match the interval delay
+		if !h.lastUpdate.IsZero() {
+			elapsed := h.since(h.lastUpdate)
+
+			// Sleep the remaining time to match the period delay
+			if elapsed < h.periodDelay && !h.sleep(h.periodDelay-elapsed) {
+				return cmd.errCode
+			}
+		}
+
+		// Check if the direction has changed, in which case it goes through neutral first
+		if h.isNeutralDetourRequired(cmd.direction) {
+			h.countNeutralDetour()
+			if rampErrCode := h.graduallySetPulseWidth(h.neutralPulseWidth); rampErrCode != tinygoerrors.ErrorCodeNil {
+				return rampErrCode
+			}
+			if h.isRampCancelled() {
+				return cmd.errCode
+			}
+		}
+
+		// Sleep the appropriate delay based on the direction change
+		if !h.sleep(h.directionChangeDelay(cmd.direction)) {
+			return cmd.errCode
+		}
+
+		// Check if the motor launches from a stop, in which case the soft start limits the acceleration
+		if h.softStartRate > 0 && !h.isSoftStartSkipped && h.direction == DirectionStop &&
+			(cmd.direction == DirectionForward || cmd.direction == DirectionBackward) {
+			h.setSoftStartDuration(time.Duration(cmd.speed / h.softStartRate * float64(time.Second)))
+		}
+
+		// Continue with the gradual change until reaching the pulse width
+		rampErrCode := h.graduallySetPulseWidth(cmd.pulse)
+		h.setSoftStartDuration(0)
+
+		// Update the current direction, even if the ramp was cancelled or aborted the pulse width is already on its side,
+		// unless the movement got disabled mid-ramp and the motor got stopped
+		direction := cmd.direction
+		if rampErrCode == ErrorCodeESCMotorMovementDisabled {
+			direction = DirectionStop
+		}
+		isDirectionChanged := h.completeDirection(direction)
+		if rampErrCode != tinygoerrors.ErrorCodeNil {
+			return rampErrCode
+		}
+		if h.isRampCancelled() {
+			return cmd.errCode
+		}
+		h.completeRamp(isDirectionChanged)
+	}
+
+	h.finishSpeedCommand(cmd)
+	return cmd.errCode
+}
+
+// setSoftStartDuration sets the duration of the soft start limiting the ramp of a launch
+//
+// Parameters:
+//
+// duration: The duration of the soft start, zero disables it
+func (h *DefaultHandler) setSoftStartDuration(duration time.Duration) {
+	h.stateMutex.Lock()
+	h.softStartDuration = duration
+	h.stateMutex.Unlock()
+}
+
+// SetSpeedWithoutSoftStart sets the ESC motor speed skipping the soft start, for the launches that are meant to be
+// aggressive.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxSpeed (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedWithoutSoftStart(
+	speed float64,
+	direction Direction,
+) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+	h.isSoftStartSkipped = true
+	errCode := h.setSpeed(speed, direction)
+	h.isSoftStartSkipped = false
+	return h.reportError(errCode, OpSetSpeed)
+}
+
+// GetSpeed returns the current speed of the ESC motor.
+//
+// Returns:
+//
+// The current speed of the ESC motor as a value between -maxBackwardSpeed (full backward) and maxForwardSpeed (full
+// forward), zero while the manual override is active.
+func (h *DefaultHandler) GetSpeed() float64 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.signedSpeed()
+}
+
+// GetSpeedWithOverride returns the current speed of the ESC motor along with whether it is stale, since a raw pulse
+// width, a replay or a calibration drives the output bypassing the speed bookkeeping.
+//
+// Returns:
+//
+// The current speed as returned by GetSpeed and true if the manual override is active, in which case the speed does
+// not reflect the output, otherwise false
+func (h *DefaultHandler) GetSpeedWithOverride() (float64, bool) {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.signedSpeed(), h.isManualOverride
+}
+
+// IsManualOverride returns whether the manual override is active, set by SetRawPulse, Replay, Calibrate and
+// CalibrateNeutral and cleared by the next speed command or stop.
+//
+// Returns:
+//
+// True if the output bypasses the speed bookkeeping, otherwise false
+func (h *DefaultHandler) IsManualOverride() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isManualOverride
+}
+
+// setManualOverride marks the speed bookkeeping as manual, reporting a zero speed until the next speed command
+func (h *DefaultHandler) setManualOverride() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.speed = 0
+	h.isManualOverride = true
+}
+
+// signedSpeed returns the current speed as commanded, before the polarity inversion
+//
+// Returns:
+//
+// The current speed, zero while a raw pulse width set by SetRawPulse is driven
+func (h *DefaultHandler) signedSpeed() float64 {
+	if h.isManualOverride {
+		return 0
+	}
+	if h.isPolarityInverted {
+		return -h.speed
+	}
+	return h.speed
+}
+
+// Stop sets the ESC motor speed to 0 (stop), driving the pulse width set by WithStopPulse. It disengages the cruise
+// mode first.
+//
+// Returns:
+//
+// An error if the speed could not be set to 0, otherwise nil.
+func (h *DefaultHandler) Stop() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+	return h.reportError(h.setSpeed(0, DirectionStop), OpStop)
+}
+
+// StopWithRamp decelerates the motor to a stop over the given duration, one step per period, e.g. for heavy props that
+// should not stop abruptly. Unlike Stop, which follows the configured ramp, and EmergencyStop, which skips it, the
+// duration overrides the ramp settings for this stop only.
+//
+// Parameters:
+//
+// duration: The time the deceleration takes, zero or less than a period stops at once
+//
+// Returns:
+//
+// An error if the duration is negative or the speed could not be set to 0, otherwise nil
+func (h *DefaultHandler) StopWithRamp(duration time.Duration) tinygoerrors.ErrorCode {
+	if duration < 0 {
+		return ErrorCodeESCMotorInvalidStopRampDuration
+	}
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Override the ramp settings for the stop, a duration shorter than a period jumps to the stop pulse width at once
+	h.stateMutex.Lock()
+	h.isStopRamp = true
+	h.stopRampDuration = duration
+	h.stateMutex.Unlock()
+	errCode := h.setSpeed(0, DirectionStop)
+	h.stateMutex.Lock()
+	h.isStopRamp = false
+	h.stopRampDuration = 0
+	h.stateMutex.Unlock()
+	return h.reportError(errCode, OpStopWithRamp)
+}
+
+// SetSpeedForward sets the ESC motor speed forward.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxForwardSpeed (full forward).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the speed is within the valid range
+	if speed < 0 {
+		speed = 0
+	}
+	if speed > h.maxForwardSpeed {
+		speed = h.maxForwardSpeed
+	}
+	return h.reportError(h.setSpeed(speed, DirectionForward), OpSetSpeedForward)
+}
+
+// SetSpeedBackward sets the ESC motor speed backward.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and maxBackwardSpeed (full backward).
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the speed is within the valid range
+	if speed < 0 {
+		speed = 0
+	}
+	if speed > h.maxBackwardSpeed {
+		speed = h.maxBackwardSpeed
+	}
+	return h.reportError(h.setSpeed(speed, DirectionBackward), OpSetSpeedBackward)
+}
+
+// SetSpeedSigned sets the ESC motor speed from a signed value, e.g. a joystick axis.
+//
+// Parameters:
+//
+// signed: Speed value, positive for forward and negative for backward, its magnitude is clamped to the max speed of
+// the direction.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedSigned(signed float64) tinygoerrors.ErrorCode {
+	return setSignedSpeed(h, signed)
+}
+
+// SetSpeedForwardPercent sets the ESC motor speed forward as a percentage.
+//
+// Parameters:
+//
+// percent: Speed value between 0 (stop) and 100 (full forward), higher values are clamped to 100.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedForwardPercent(percent uint8) tinygoerrors.ErrorCode {
+	return h.SetSpeedForward(percentToSpeed(percent))
+}
+
+// SetSpeedBackwardPercent sets the ESC motor speed backward as a percentage.
+//
+// Parameters:
+//
+// percent: Speed value between 0 (stop) and 100 (full backward), higher values are clamped to 100.
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil.
+func (h *DefaultHandler) SetSpeedBackwardPercent(percent uint8) tinygoerrors.ErrorCode {
+	return h.SetSpeedBackward(percentToSpeed(percent))
+}
+
+// GetSpeedPercent returns the current speed of the ESC motor as a percentage.
+//
+// Returns:
+//
+// The current speed rounded to the nearest whole percent, between -100 (full backward) and 100 (full forward).
+func (h *DefaultHandler) GetSpeedPercent() int8 {
+	return int8(math.Round(h.GetSpeed() * 100))
+}
+
+// IsStopped returns whether the motor is stopped, with the stop direction committed and the stop pulse width driven.
+//
+// Returns:
+//
+// True if the motor is stopped, otherwise false
+func (h *DefaultHandler) IsStopped() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.direction == DirectionStop && h.isPulseWritten && h.pulse == h.stopPulseWidth()
+}
+
+// IsMoving returns whether the motor is not stopped, which includes braking and an interrupted ramp.
+//
+// Returns:
+//
+// True if the motor is not stopped, otherwise false
+func (h *DefaultHandler) IsMoving() bool {
+	return !h.IsStopped()
+}
+
+// GetPeakSpeed returns the peak speed observed since the handler was created or the peaks were reset.
+//
+// Returns:
+//
+// The peak speed as a value between 0 and 1, regardless of the direction
+func (h *DefaultHandler) GetPeakSpeed() float64 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.peakSpeed
+}
+
+// GetPeakPulse returns the peak pulse width observed since the handler was created or the peaks were reset.
+//
+// Returns:
+//
+// The pulse width written to the PWM channel that was the furthest from the neutral pulse width
+func (h *DefaultHandler) GetPeakPulse() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.peakPulse
+}
+
+// ResetPeaks clears the peak speed and peak pulse width observed.
+func (h *DefaultHandler) ResetPeaks() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.peakSpeed = 0
+	h.peakPulse = h.neutralPulseWidth
+}
+
+// SetZeroCrossDwell sets the time to dwell at neutral whenever a ramp lands on or crosses the neutral pulse width.
+//
+// Parameters:
+//
+// dwell: The time to dwell at neutral, zero or negative disables it
+func (h *DefaultHandler) SetZeroCrossDwell(dwell time.Duration) {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.zeroCrossDwell = dwell
+	h.stateMutex.Unlock()
+}
+
+// GetPulseStep returns the pulse width step of the gradual ramps.
+//
+// Returns:
+//
+// The pulse width step and whether it is set, the pulse width jumps to the target at once if it is not
+func (h *DefaultHandler) GetPulseStep() (uint32, bool) {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	if h.pulseStep == nil {
+		return 0, false
+	}
+	return *h.pulseStep, true
+}
+
+// SetPulseStep sets the pulse width step of the gradual ramps at runtime. The ramp duration still takes precedence
+// over it when set.
+//
+// Parameters:
+//
+// step: The pulse width step, nil jumps to the target at once
+//
+// Returns:
+//
+// An error if the step is zero, otherwise nil
+func (h *DefaultHandler) SetPulseStep(step *uint32) tinygoerrors.ErrorCode {
+	// Check if the step is zero, which would never reach the target
+	if step != nil && *step == 0 {
+		return ErrorCodeESCMotorInvalidPulseStep
+	}
+
+	// Copy the step so the caller cannot change it behind the handler, nil makes the ramps jump to the target at once
+	var pulseStep *uint32
+	if step != nil {
+		stepCopy := *step
+		pulseStep = &stepCopy
+	}
+
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.pulseStep = pulseStep
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// IsPolarityInverted returns whether the motor polarity is inverted.
+//
+// Returns:
+//
+// True if the forward and backward directions are swapped, otherwise false
+func (h *DefaultHandler) IsPolarityInverted() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isPolarityInverted
+}
+
+// GetPolarityInverted returns whether the motor polarity is inverted, the counterpart of SetPolarityInverted.
+//
+// Returns:
+//
+// True if the forward and backward directions are swapped, otherwise false
+func (h *DefaultHandler) GetPolarityInverted() bool {
+	return h.IsPolarityInverted()
+}
+
+// SetPolarityInverted sets whether the motor polarity is inverted, e.g. when the same firmware drives mirror-image
+// motors. If the motor is moving, the commanded direction and speed are kept and re-driven with the new polarity,
+// going through neutral and the direction-change delays like any other reversal.
+//
+// Parameters:
+//
+// isPolarityInverted: Whether the motor polarity is inverted
+//
+// Returns:
+//
+// An error if the handler has been closed, the motor is unidirectional and cannot be inverted, or the speed could not
+// be re-driven, otherwise nil
+func (h *DefaultHandler) SetPolarityInverted(isPolarityInverted bool) tinygoerrors.ErrorCode {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpSetPolarityInverted)
+	}
+
+	// Check if the motor is unidirectional, in which case it cannot go backward
+	if isPolarityInverted && h.isUnidirectional {
+		return h.reportError(ErrorCodeESCMotorBackwardNotSupported, OpSetPolarityInverted)
+	}
+
+	// Check if the polarity is unchanged
+	if isPolarityInverted == h.isPolarityInverted {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Get the commanded direction before swapping the polarity
+	direction := h.commandedDirection()
+	h.stateMutex.Lock()
+	h.isPolarityInverted = isPolarityInverted
+	h.stateMutex.Unlock()
+
+	// Re-drive the commanded speed with the new polarity if the motor is moving
+	if h.isManualOverride || (direction != DirectionForward && direction != DirectionBackward) {
+		return tinygoerrors.ErrorCodeNil
+	}
+	return h.reportError(h.setSpeed(h.speedMagnitude(), direction), OpSetPolarityInverted)
+}
+
+// SetSignalInverted sets whether the PWM signal is inverted, e.g. when the ESC is driven through an inverting buffer.
+//
+// Parameters:
+//
+// isSignalInverted: Whether the PWM signal is inverted
+//
+// Returns:
+//
+// An error if the current pulse width could not be re-driven with the new signal inversion, otherwise nil
+func (h *DefaultHandler) SetSignalInverted(isSignalInverted bool) tinygoerrors.ErrorCode {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.isSignalInverted = isSignalInverted
+	h.stateMutex.Unlock()
+
+	// Re-drive the current pulse width with the new signal inversion
+	return h.rewritePulseWidth()
+}
+
+// IsSignalInverted returns whether the PWM signal is inverted.
+//
+// Returns:
+//
+// True if the pulse is carried by the low time of the PWM period, otherwise false
+func (h *DefaultHandler) IsSignalInverted() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isSignalInverted
+}
+
+// SetDelayScale sets the factor applied to both direction-change delays when they are used, without modifying the
+// configured base delays.
+//
+// Parameters:
+//
+// factor: The factor to scale the delays by, 1 restores the base delays
+//
+// Returns:
+//
+// An error if the factor is negative, otherwise nil
+func (h *DefaultHandler) SetDelayScale(factor float64) tinygoerrors.ErrorCode {
+	if factor < 0 {
+		return ErrorCodeESCMotorInvalidDelayScale
+	}
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.delayScale = factor
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// SetDirectionDelays sets the base direction-change delays at runtime, the delay scale still applies to them.
+//
+// Parameters:
+//
+// backwardToForwardDelay: Delay when changing direction from backward to forward, zero or positive
+// forwardToBackwardDelay: Delay when changing direction from forward to backward, zero or positive
+//
+// Returns:
+//
+// An error if any delay is negative, otherwise nil
+func (h *DefaultHandler) SetDirectionDelays(
+	backwardToForwardDelay time.Duration,
+	forwardToBackwardDelay time.Duration,
+) tinygoerrors.ErrorCode {
+	if backwardToForwardDelay < 0 || forwardToBackwardDelay < 0 {
+		return ErrorCodeESCMotorInvalidDirectionDelay
+	}
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.backwardToForwardDelay = backwardToForwardDelay
+	h.forwardToBackwardDelay = forwardToBackwardDelay
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// LockNeutral engages the neutral lock, driving the motor to neutral and holding it there regardless of the commanded
+// speed until UnlockNeutral is called. It disengages the cruise mode first.
+//
+// Returns:
+//
+// An error if the motor could not be stopped, otherwise nil
+func (h *DefaultHandler) LockNeutral() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.isNeutralLocked = true
+	h.stateMutex.Unlock()
+
+	// Log the neutral lock
+	if h.logger != nil {
+		h.logger.AddMessage(
+			lockNeutralPrefix,
+			true,
+		)
+		h.logger.Debug()
+	}
+	return h.reportError(h.setSpeed(0, DirectionStop), OpStop)
+}
+
+// UnlockNeutral releases the neutral lock, the motor stays at neutral until the next speed command.
+func (h *DefaultHandler) UnlockNeutral() {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.isNeutralLocked = false
+	h.stateMutex.Unlock()
+
+	// Log the neutral unlock
+	if h.logger != nil {
+		h.logger.AddMessage(
+			unlockNeutralPrefix,
+			true,
+		)
+		h.logger.Debug()
+	}
+}
+
+// IsNeutralLocked returns whether the neutral lock is engaged.
+//
+// Returns:
+//
+// True if the motor is held at neutral, otherwise false
+func (h *DefaultHandler) IsNeutralLocked() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isNeutralLocked
+}
+
+// SetForwardStartOffset sets the pulse width offset from neutral where the forward mapping starts for any speed above
+// zero, compensating ESCs that ignore the pulses closest to neutral.
+//
+// Parameters:
+//
+// offset: The pulse width offset above the neutral pulse width
+//
+// Returns:
+//
+// An error if the offset leaves no forward span, otherwise nil
+func (h *DefaultHandler) SetForwardStartOffset(offset uint32) tinygoerrors.ErrorCode {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	if offset >= h.maxPulseWidth-h.neutralPulseWidth {
+		return ErrorCodeESCMotorInvalidStartOffset
+	}
+	h.stateMutex.Lock()
+	h.forwardStartOffset = offset
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// SetBackwardStartOffset sets the pulse width offset from neutral where the backward mapping starts for any speed
+// above zero, compensating ESCs that ignore the pulses closest to neutral.
+//
+// Parameters:
+//
+// offset: The pulse width offset below the neutral pulse width
+//
+// Returns:
+//
+// An error if the offset leaves no backward span, otherwise nil
+func (h *DefaultHandler) SetBackwardStartOffset(offset uint32) tinygoerrors.ErrorCode {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	if offset >= h.neutralPulseWidth-h.minPulseWidth {
+		return ErrorCodeESCMotorInvalidStartOffset
+	}
+	h.stateMutex.Lock()
+	h.backwardStartOffset = offset
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// StepTowardNeutral reduces the commanded speed by one notch toward zero regardless of the direction, stopping the
+// motor once the speed reaches zero.
+//
+// Parameters:
+//
+// notch: Speed value between 0 and 1 to reduce the current speed by
+//
+// Returns:
+//
+// An error if the notch is out of range or the speed could not be set, otherwise nil
+func (h *DefaultHandler) StepTowardNeutral(notch float64) tinygoerrors.ErrorCode {
+	// Check if the notch is within the valid range
+	if notch <= 0 || notch > 1 {
+		return ErrorCodeESCMotorSpeedOutOfRange
+	}
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Get the speed magnitude after the notch
+	speed := h.speedMagnitude() - notch
+
+	// Stop the motor if the notch reaches zero
+	if h.direction == DirectionStop || speed <= 0 {
+		return h.reportError(h.setSpeed(0, DirectionStop), OpStop)
+	}
+
+	// Re-apply the reduced speed in the commanded direction
+	return h.reportError(h.setSpeed(speed, h.commandedDirection()), OpSetSpeed)
+}
+
+// IsUnidirectional returns whether the motor is unidirectional.
+//
+// Returns:
+//
+// True if the motor maps the speed across the min and max pulse widths and cannot go backward, otherwise false
+func (h *DefaultHandler) IsUnidirectional() bool {
+	return h.isUnidirectional
+}
+
+// SetOnError sets the function called with the error code and the operation label whenever an operation fails.
+//
+// Parameters:
+//
+// onError: Function to call on every failed operation, nil disables it
+func (h *DefaultHandler) SetOnError(onError func(errCode tinygoerrors.ErrorCode, op string)) {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.onError = onError
+}
+
+// GetRemainingRampSteps returns how many pulse width steps remain before the current ramp reaches its target.
+//
+// Returns:
+//
+// The number of remaining steps, zero if the ramp is complete
+func (h *DefaultHandler) GetRemainingRampSteps() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.remainingRampSteps()
+}
+
+// remainingRampSteps returns how many pulse width steps remain before the current ramp reaches its target
+//
+// Returns:
+//
+// The number of remaining steps, zero if the ramp is complete
+func (h *DefaultHandler) remainingRampSteps() uint32 {
+	distance := pulseDistance(h.rampTarget, h.pulse)
+	if distance == 0 {
+		return 0
+	}
+
+	// Check if the pulse width jumps to the target in a single step
+	if h.rampStep == 0 {
+		return 1
+	}
+	return divideRoundingUp(distance, h.rampStep)
+}
+
+// estimateRampDuration estimates how long graduallySetPulseWidth blocks to ramp between two pulse widths
+//
+// Parameters:
+//
+// from: The pulse width at the start of the ramp
+// to: The target pulse width of the ramp
+// softStartDuration: The duration of the soft start limiting the ramp, zero if it does not apply
+//
+// Returns:
+//
+// The estimated ramp duration
+func (h *DefaultHandler) estimateRampDuration(from, to uint32, softStartDuration time.Duration) time.Duration {
+	var duration time.Duration
+
+	// Add the sleep of every intermediate step
+	if steps := h.rampSteps(from, to, softStartDuration); steps > 1 {
+		duration += time.Duration(steps-1) * h.periodDelay
+	}
+
+	// Add the dwell if the ramp passes through neutral
+	if h.zeroCrossDwell > 0 && from != h.neutralPulseWidth && to != h.neutralPulseWidth &&
+		(from < h.neutralPulseWidth) != (to < h.neutralPulseWidth) {
+		duration += h.zeroCrossDwell
+	}
+	return duration
+}
+
+// EstimateSetSpeedDuration estimates how long SetSpeed would block for the given command, including the period
+// catch-up, the neutral pass-through on a direction change, the direction-change delay, the soft start and the ramp.
+// The command is resolved like SetSpeed does, with the input smoothing, the boost threshold, the brake mode and the
+// last sensed thermal and current ceilings, but without side effects besides evaluating the movement enabled function.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// The estimated blocking time, zero if the command is invalid or does not change the pulse width
+func (h *DefaultHandler) EstimateSetSpeedDuration(speed float64, direction Direction) time.Duration {
+	// Check if movement is disabled before reading the state, the movement enabled function may call the getters
+	if h.isMovementDisabled() {
+		return 0
+	}
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+
+	// Check if the command is valid
+	if h.checkSpeedCommand(speed, direction) != tinygoerrors.ErrorCodeNil {
+		return 0
+	}
+
+	// Resolve the command with the last sensed ceilings
+	ceiling := h.thermalCeiling
+	if h.isCurrentLimited && h.currentCeiling < ceiling {
+		ceiling = h.currentCeiling
+	}
+	cmd, errCode := h.evaluateSpeedCommand(speed, direction, ceiling)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return 0
+	}
+
+	// Check if the pulse width would be set
+	if h.isPulseWritten && h.pulse == cmd.pulse {
+		return 0
+	}
+
+	// Add the remaining time to match the period delay
+	var catchUp time.Duration
+	if !h.lastUpdate.IsZero() {
+		if elapsed := h.since(h.lastUpdate); elapsed < h.periodDelay {
+			catchUp = h.periodDelay - elapsed
+		}
+	}
+	duration := catchUp
+
+	// Add the neutral pass-through on a direction change
+	from := h.pulse
+	if h.isNeutralDetourRequired(cmd.direction) {
+		duration += h.estimateRampDuration(from, h.neutralPulseWidth, 0)
+		from = h.neutralPulseWidth
+	}
+
+	// Add the direction-change delay, the time spent at neutral during the period catch-up counts toward it
+	delay := h.directionChangeDelay(cmd.direction)
+	if !h.lastStopTime.IsZero() {
+		delay -= catchUp
+	}
+	if delay > 0 {
+		duration += delay
+	}
+
+	// Check if the motor launches from a stop, in which case the soft start limits the acceleration
+	var softStartDuration time.Duration
+	if h.softStartRate > 0 && h.direction == DirectionStop &&
+		(cmd.direction == DirectionForward || cmd.direction == DirectionBackward) {
+		softStartDuration = time.Duration(cmd.speed / h.softStartRate * float64(time.Second))
+	}
+
+	// Add the ramp to the target pulse width
+	return duration + h.estimateRampDuration(from, cmd.pulse, softStartDuration)
+}
+
+// Events returns the channel of state-change events. The channel is buffered with EventBufferSize events and new
+// events are dropped while it is full, so a single consumer should drain it.
+//
+// Returns:
+//
+// The receive-only channel of events
+func (h *DefaultHandler) Events() <-chan Event {
+	return h.events
+}
+
+// GetLastRampDuration returns how long the last ramp took, including its zero-cross dwell, for profiling.
+//
+// Returns:
+//
+// The duration of the last ramp, zero if no ramp has run yet
+func (h *DefaultHandler) GetLastRampDuration() time.Duration {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.lastRampDuration
+}
+
+// GetRampCount returns how many ramps moved the pulse width since the handler was created, including the ramps to
+// neutral of the direction changes and the ramps run by a MotorGroup.
+//
+// Returns:
+//
+// The number of ramps
+func (h *DefaultHandler) GetRampCount() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.rampCount
+}
+
+// GetTotalRampDuration returns how long the ramps counted by GetRampCount took altogether.
+//
+// Returns:
+//
+// The total duration of the ramps
+func (h *DefaultHandler) GetTotalRampDuration() time.Duration {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.totalRampDuration
+}
+
+// GetNeutralDetourCount returns how many direction changes passed through neutral before driving the new direction
+// since the handler was created. The instant reversals and the brakes do not pass through neutral and are not counted.
+//
+// Returns:
+//
+// The number of neutral detours
+func (h *DefaultHandler) GetNeutralDetourCount() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.neutralDetourCount
+}
+
+// countNeutralDetour counts a direction change passing through neutral
+func (h *DefaultHandler) countNeutralDetour() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.neutralDetourCount++
+}
+
+// GetLastStopTime returns when the pulse width was last set to or crossed neutral, the time the direction-change
+// delays count from.
+//
+// Returns:
+//
+// The last stop time, zero if the motor has not stopped yet or is driven in a direction
+func (h *DefaultHandler) GetLastStopTime() time.Time {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.lastStopTime
+}
+
+// GetLastUpdate returns when the last speed update completed, the time the period delay catch-up counts from.
+//
+// Returns:
+//
+// The last update time, zero if no update has completed yet
+func (h *DefaultHandler) GetLastUpdate() time.Time {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.lastUpdate
+}
+
+// GetPulse returns the pulse width currently driven on the PWM channel.
+//
+// Returns:
+//
+// The last pulse width written to the PWM channel
+func (h *DefaultHandler) GetPulse() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.pulse
+}
+
+// GetMinPulseWidth returns the minimum pulse width of the ESC motor.
+//
+// Returns:
+//
+// The minimum pulse width
+func (h *DefaultHandler) GetMinPulseWidth() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.minPulseWidth
+}
+
+// GetNeutralPulseWidth returns the neutral pulse width of the ESC motor, with the neutral trim applied.
+//
+// Returns:
+//
+// The neutral pulse width
+func (h *DefaultHandler) GetNeutralPulseWidth() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.neutralPulseWidth
+}
+
+// GetMaxPulseWidth returns the maximum pulse width of the ESC motor.
+//
+// Returns:
+//
+// The maximum pulse width
+func (h *DefaultHandler) GetMaxPulseWidth() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.maxPulseWidth
+}
+
+// GetFrequency returns the frequency of the PWM signal.
+//
+// Returns:
+//
+// The frequency in Hz
+func (h *DefaultHandler) GetFrequency() uint16 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.frequency
+}
+
+// GetPeriod returns the period of the PWM signal used to compute the duty cycle.
+//
+// Returns:
+//
+// The period in nanoseconds
+func (h *DefaultHandler) GetPeriod() uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.period
+}
+
+// GetPeriodDelay returns the delay between two consecutive pulse width updates.
+//
+// Returns:
+//
+// The period delay
+func (h *DefaultHandler) GetPeriodDelay() time.Duration {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.periodDelay
+}
+
+// cancelAsyncRamp cancels the in-flight async ramp, if any, without waiting for its goroutine to return
+func (h *DefaultHandler) cancelAsyncRamp() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	if h.rampCancel == nil {
+		return
+	}
+
+	// Check if the ramp has already been cancelled
+	select {
+	case <-h.rampCancel:
+	default:
+		close(h.rampCancel)
+	}
+}
+
+// lockCommand cancels the in-flight async ramp, if any, and locks the handler for a command
+func (h *DefaultHandler) lockCommand() {
+	h.cancelAsyncRamp()
+	h.waitAndLock()
+}
+
+// waitAndLock locks the handler for a command once the running command returns, letting the in-flight async ramp, if
+// any, complete
+func (h *DefaultHandler) waitAndLock() {
+	h.commandMutex.Lock()
+
+	// Release the channels of the last async ramp, its goroutine has returned once the handler is locked
+	if h.rampDone != nil {
+		h.stateMutex.Lock()
+		h.rampCancel = nil
+		h.rampDone = nil
+		h.stateMutex.Unlock()
+	}
+}
+
+// SetSpeedAsync sets the ESC motor speed from a background goroutine and returns immediately. A subsequent
+// SetSpeedAsync call cancels the in-flight ramp and starts ramping from the pulse width reached toward the new target.
+//
+// The blocking commands that drive the motor, e.g. SetSpeed and Stop, also cancel the in-flight ramp and wait for its
+// goroutine to return before running, while the setters wait for the ramp to complete. Errors of the background ramp
+// are reported through the error callback with the OpSetSpeedAsync label.
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the command is invalid, otherwise nil
+func (h *DefaultHandler) SetSpeedAsync(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	if errCode := h.checkAsyncCommand(speed, direction); errCode != tinygoerrors.ErrorCodeNil {
+		errCode = h.reportError(errCode, OpSetSpeedAsync)
+		h.commandMutex.Unlock()
+		return errCode
+	}
+
+	// Start the ramp in the background, handing the lock of the handler over to its goroutine
+	rampCancel := make(chan struct{})
+	rampDone := make(chan struct{})
+	h.stateMutex.Lock()
+	h.rampCancel = rampCancel
+	h.rampDone = rampDone
+	h.stateMutex.Unlock()
+	go func() {
+		defer h.commandMutex.Unlock()
+		defer close(rampDone)
+		_ = h.reportError(h.setSpeed(speed, direction), OpSetSpeedAsync)
+	}()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// checkAsyncCommand checks a speed command before starting its ramp in the background
+//
+// Parameters:
+//
+// speed: Speed value between 0 (stop) and 1 (full speed).
+// direction: Direction of the motor.
+//
+// Returns:
+//
+// An error if the handler has been closed or the command is invalid, otherwise nil
+func (h *DefaultHandler) checkAsyncCommand(speed float64, direction Direction) tinygoerrors.ErrorCode {
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Check if the speed is within the valid range
+	if speed < 0 || speed > 1 {
+		return ErrorCodeESCMotorSpeedOutOfRange
+	}
+
+	// Check if the direction is known
+	if direction != DirectionStop && direction != DirectionForward && direction != DirectionBackward {
+		return ErrorCodeESCMotorUnknownDirection
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// RampDone returns a channel that is closed once the in-flight async ramp completes or is cancelled.
+//
+// Returns:
+//
+// The channel signaling the completion of the async ramp, already closed if there is none
+func (h *DefaultHandler) RampDone() <-chan struct{} {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	if h.rampDone == nil {
+		return closedChannel
+	}
+	return h.rampDone
+}
+
+// Wait blocks until the in-flight async ramp completes or is cancelled.
+func (h *DefaultHandler) Wait() {
+	<-h.RampDone()
+}
+
+// logPulseWidth logs a message with a pulse width
+//
+// Parameters:
+//
+// prefix: The prefix of the log message
+// pulse: The pulse width to log
+func (h *DefaultHandler) logPulseWidth(prefix []byte, pulse uint32) {
+	if h.logger != nil {
+		h.logger.AddMessageWithUint32(
+			prefix,
+			pulse,
+			true,
+			true,
+			false,
+		)
+		h.logger.Debug()
+	}
+}
+
+// Calibrate runs the ESC endpoint calibration by driving the max pulse width, then the min pulse width, and finally
+// returning to neutral. The pulse widths are written directly, without ramping, since the ESC must see the endpoints.
+//
+// It refuses to run while the motor is armed or movement is enabled, so a movement enabled function returning false is
+// required to calibrate.
+//
+// Parameters:
+//
+// highHoldTime: Time to hold the max pulse width, usually until the ESC beeps
+// lowHoldTime: Time to hold the min pulse width, usually until the ESC beeps
+//
+// Returns:
+//
+// An error if movement is enabled, otherwise nil
+func (h *DefaultHandler) Calibrate(highHoldTime, lowHoldTime time.Duration) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpCalibrate)
+	}
+
+	// Check if the motor is armed or movement is enabled
+	if h.isArmed || h.isMovementEnabled == nil || h.isMovementEnabled() {
+		return h.reportError(ErrorCodeESCMotorCalibrationWhileArmed, OpCalibrate)
+	}
+
+	// Mark the speed bookkeeping as manual until the next speed command
+	h.setManualOverride()
+
+	// Drive the high endpoint
+	h.logPulseWidth(calibrateHighPrefix, h.maxPulseWidth)
+	errCode := h.writePulseWidth(h.maxPulseWidth)
+	if errCode == tinygoerrors.ErrorCodeNil {
+		h.clock.Sleep(highHoldTime)
+
+		// Drive the low endpoint
+		h.logPulseWidth(calibrateLowPrefix, h.minPulseWidth)
+		errCode = h.writePulseWidth(h.minPulseWidth)
+		if errCode == tinygoerrors.ErrorCodeNil {
+			h.clock.Sleep(lowHoldTime)
+		}
+	}
+
+	// Return to neutral, even if a calibration phase failed
+	h.logPulseWidth(calibrateNeutralPrefix, h.neutralPulseWidth)
+	if neutralErrCode := h.writePulseWidth(h.neutralPulseWidth); errCode == tinygoerrors.ErrorCodeNil {
+		errCode = neutralErrCode
+	}
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.direction = DirectionStop
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	return h.reportError(errCode, OpCalibrate)
+}
+
+// CalibrateNeutral runs the assisted neutral calibration, sweeping the neutral trim across the search range in
+// NeutralCalibrationStep steps, holding every trial for NeutralCalibrationSettleTime and reading the RPM, then storing
+// the trim where the measured RPM is the closest to zero. The pulse widths are written directly, without ramping.
+//
+// It must run with the motor on a stand, so like Calibrate it refuses to run while the motor is armed or movement is
+// enabled.
+//
+// Parameters:
+//
+// rpmSource: Function returning the measured RPM, signed or not
+// searchRange: The largest neutral trim to try on each side of the configured neutral pulse width, in nanoseconds
+//
+// Returns:
+//
+// The neutral trim found and an error if movement is enabled, the RPM source is nil or a trial could not be driven, in
+// which case the previous trim is restored, otherwise nil
+func (h *DefaultHandler) CalibrateNeutral(
+	rpmSource func() float64,
+	searchRange uint32,
+) (int32, tinygoerrors.ErrorCode) {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.neutralTrim, h.reportError(ErrorCodeESCMotorClosed, OpCalibrateNeutral)
+	}
+
+	// Check if the motor is armed or movement is enabled
+	if h.isArmed || h.isMovementEnabled == nil || h.isMovementEnabled() {
+		return h.neutralTrim, h.reportError(ErrorCodeESCMotorCalibrationWhileArmed, OpCalibrateNeutral)
+	}
+
+	// Check if the motor has a neutral to trim and the RPM can be measured
+	if h.isUnidirectional {
+		return h.neutralTrim, h.reportError(ErrorCodeESCMotorNeutralTrimNotSupported, OpCalibrateNeutral)
+	}
+	if rpmSource == nil {
+		return h.neutralTrim, h.reportError(ErrorCodeESCMotorNilRPMSource, OpCalibrateNeutral)
+	}
+
+	// Bound the search range to the pulse width span, the trim is clamped inside it anyway
+	if span := h.maxPulseWidth - h.minPulseWidth; searchRange > span {
+		searchRange = span
+	}
+
+	// Mark the speed bookkeeping as manual until the next speed command
+	h.setManualOverride()
+
+	// Try every trim, keeping the one with the RPM closest to zero and, on a tie, the smallest trim
+	previousTrim := h.neutralTrim
+	bestTrim := previousTrim
+	bestRPM := math.Inf(1)
+	var errCode tinygoerrors.ErrorCode
+	for offset := -int64(searchRange); offset <= int64(searchRange); offset += int64(NeutralCalibrationStep) {
+		h.stateMutex.Lock()
+		h.setNeutralTrim(int32(offset))
+		trim := h.neutralTrim
+		h.stateMutex.Unlock()
+		if errCode = h.writePulseWidth(h.neutralPulseWidth); errCode != tinygoerrors.ErrorCodeNil {
+			break
+		}
+		h.clock.Sleep(NeutralCalibrationSettleTime)
+		rpm := math.Abs(rpmSource())
+
+		// Log the trial
+		if h.logger != nil {
+			h.logger.AddMessageWithUint32(
+				calibrateNeutralTrialPrefix,
+				h.neutralPulseWidth,
+				true,
+				false,
+				false,
+			)
+			h.logger.AddMessageWithFloat64(
+				calibrateNeutralRPMPrefix,
+				rpm,
+				h.floatPrecision,
+				true,
+				true,
+			)
+			h.logger.Debug()
+		}
+
+		// Check if the trial is the closest to zero so far
+		if rpm < bestRPM || (rpm == bestRPM && math.Abs(float64(trim)) < math.Abs(float64(bestTrim))) {
+			bestRPM = rpm
+			bestTrim = trim
+		}
+	}
+
+	// Store the trim found, restoring the previous one if a trial failed
+	if errCode != tinygoerrors.ErrorCodeNil {
+		bestTrim = previousTrim
+	}
+	h.stateMutex.Lock()
+	h.setNeutralTrim(bestTrim)
+	h.stateMutex.Unlock()
+
+	// Return to the trimmed neutral, even if a trial failed
+	h.logPulseWidth(calibrateNeutralPrefix, h.neutralPulseWidth)
+	if neutralErrCode := h.writePulseWidth(h.neutralPulseWidth); errCode == tinygoerrors.ErrorCodeNil {
+		errCode = neutralErrCode
+	}
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.direction = DirectionStop
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	return h.neutralTrim, h.reportError(errCode, OpCalibrateNeutral)
+}
+
+// PlayBeep drives a pattern of pulse widths, e.g. the sequences that make some ESCs play a confirmation or a locate
+// tone, then restores the previous pulse width. It only runs while the motor is stopped or coasting, or disarmed while
+// arming is required.
+//
+// Parameters:
+//
+// pattern: The steps of the pattern, every pulse width between the min and max pulse widths
+//
+// Returns:
+//
+// An error if the handler has been closed, the motor is moving, a pulse width is out of range or it could not be
+// written, otherwise nil
+func (h *DefaultHandler) PlayBeep(pattern []BeepStep) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpPlayBeep)
+	}
+
+	// Check if the motor is moving, since the pattern would jump the pulse width mid-motion, unless it is disarmed while
+	// arming is required
+	isDisarmed := h.isArmRequired && !h.isArmed
+	if !isDisarmed && h.direction != DirectionStop && h.direction != DirectionCoast {
+		return h.reportError(ErrorCodeESCMotorNotStopped, OpPlayBeep)
+	}
+
+	// Check if every pulse width is within the valid range before driving any of them
+	for _, step := range pattern {
+		if step.Pulse < h.minPulseWidth || step.Pulse > h.maxPulseWidth {
+			return h.reportError(ErrorCodeESCMotorRawPulseOutOfRange, OpPlayBeep)
+		}
+	}
+
+	// Drive every step, stopping at the first failure, the stop pulse width is restored if none was written before
+	previous := h.pulse
+	if !h.isPulseWritten {
+		previous = h.stopPulseWidth()
+	}
+	var errCode tinygoerrors.ErrorCode
+	for _, step := range pattern {
+		// Feed the failsafe watchdog
+		h.Feed()
+		if errCode = h.writePulseWidth(step.Pulse); errCode != tinygoerrors.ErrorCodeNil {
+			break
+		}
+		h.clock.Sleep(step.Duration)
+	}
+
+	// Restore the previous pulse width, even if a step failed
+	if restoreErrCode := h.writePulseWidth(previous); errCode == tinygoerrors.ErrorCodeNil {
+		errCode = restoreErrCode
+	}
+	return h.reportError(errCode, OpPlayBeep)
+}
+
+// SelfTest verifies the wiring by driving the neutral, min and max pulse widths for one period each and returning to
+// neutral. If the PWM can read back the value of its channels, every channel driven by the handler is checked against
+// the value expected for the pulse width within SelfTestTolerance, otherwise the range is just exercised.
+//
+// It drives the endpoints without ramping, so like Calibrate it refuses to run while the motor is armed or movement is
+// enabled.
+//
+// Returns:
+//
+// An error if movement is enabled, a pulse width could not be written or a read-back value mismatches, otherwise nil
+func (h *DefaultHandler) SelfTest() tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpSelfTest)
+	}
+
+	// Check if the motor is armed or movement is enabled
+	if h.isArmed || h.isMovementEnabled == nil || h.isMovementEnabled() {
+		return h.reportError(ErrorCodeESCMotorCalibrationWhileArmed, OpSelfTest)
+	}
+
+	// Mark the speed bookkeeping as manual until the next speed command
+	h.setManualOverride()
+
+	// Drive every phase, stopping at the first failure
+	var errCode tinygoerrors.ErrorCode
+	for _, pulse := range [...]uint32{h.neutralPulseWidth, h.minPulseWidth, h.maxPulseWidth} {
+		h.logPulseWidth(selfTestPrefix, pulse)
+		if errCode = h.writePulseWidth(pulse); errCode != tinygoerrors.ErrorCodeNil {
+			break
+		}
+		h.clock.Sleep(h.periodDelay)
+		if errCode = h.checkReadBack(pulse); errCode != tinygoerrors.ErrorCodeNil {
+			break
+		}
+	}
+
+	// Return to neutral, even if a phase failed
+	h.logPulseWidth(selfTestPrefix, h.neutralPulseWidth)
+	if neutralErrCode := h.writePulseWidth(h.neutralPulseWidth); errCode == tinygoerrors.ErrorCodeNil {
+		errCode = neutralErrCode
+	}
+	h.completeDirection(DirectionStop)
+	h.stateMutex.Lock()
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	return h.reportError(errCode, OpSelfTest)
+}
+
+// checkReadBack checks the value read back from every channel against the value expected for the pulse width
+//
+// Parameters:
+//
+// pulse: The pulse width written
+//
+// Returns:
+//
+// ErrorCodeESCMotorSelfTestFailed if a read-back value mismatches, otherwise nil, also if the PWM cannot read back
+func (h *DefaultHandler) checkReadBack(pulse uint32) tinygoerrors.ErrorCode {
+	reader, ok := h.pwm.(pwmDutyReader)
+	if !ok || h.isDryRun || h.period == 0 {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Get the value the duty cycle of the pulse width sets
+	duty := pulse
+	if h.isSignalInverted {
+		duty = h.period - pulse
+	}
+	expected := uint32(float64(h.pwm.Top()) * float64(duty) / float64(h.period))
+
+	// Check every channel driven by the handler
+	channels := append([]uint8{h.channel}, h.extraChannels...)
+	for _, channel := range channels {
+		if value := reader.Get(channel); pulseDistance(value, expected) > SelfTestTolerance {
+			if h.logger != nil {
+				h.logger.AddMessageWithUint32(
+					selfTestFailedPrefix,
+					value,
+					true,
+					true,
+					false,
+				)
+				h.logger.Error()
+			}
+			return ErrorCodeESCMotorSelfTestFailed
+		}
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// Arm runs the arming sequence by driving the stop pulse width continuously for the hold time, which is what most
+// ESC firmwares expect after power-up before accepting throttle commands.
+//
+// Parameters:
+//
+// holdTime: Time to hold the stop pulse width
+//
+// Returns:
+//
+// An error if the motor could not be stopped, otherwise nil
+func (h *DefaultHandler) Arm(holdTime time.Duration) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Stop the motor and make sure the stop pulse width is being driven
+	if errCode := h.setSpeed(0, DirectionStop); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpArm)
+	}
+	if errCode := h.writePulseWidth(h.stopPulseWidth()); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpArm)
+	}
+	h.clock.Sleep(holdTime)
+	h.stateMutex.Lock()
+	h.isArmed = true
+	h.stateMutex.Unlock()
+
+	// Log the arming
+	if h.logger != nil {
+		h.logger.AddMessage(
+			armPrefix,
+			true,
+		)
+		h.logger.Debug()
+	}
+	h.emitEvent(EventTypeArmed, tinygoerrors.ErrorCodeNil)
+	return tinygoerrors.ErrorCodeNil
+}
+
+// Disarm stops the motor and clears the armed flag, so the motor refuses to move until the arming sequence runs again,
+// even if arming was not required on creation. It disengages the cruise mode first.
+//
+// Returns:
+//
+// An error if the motor could not be stopped, otherwise nil
+func (h *DefaultHandler) Disarm() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Stop the motor
+	if errCode := h.setSpeed(0, DirectionStop); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpDisarm)
+	}
+
+	// Require the arming sequence before moving again
+	h.stateMutex.Lock()
+	h.isArmed = false
+	h.isArmRequired = true
+	h.stateMutex.Unlock()
+
+	// Log the disarming
+	if h.logger != nil {
+		h.logger.AddMessage(
+			disarmPrefix,
+			true,
+		)
+		h.logger.Debug()
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// IsArmed returns whether the arming sequence has completed.
+//
+// Returns:
+//
+// True if the motor is armed, otherwise false
+func (h *DefaultHandler) IsArmed() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isArmed
+}
+
+// runWatchdog checks periodically if a command arrived within the failsafe timeout, stopping the motor otherwise, and
+// if the sensed current exceeds the current limit, pulling the motor back toward neutral
+func (h *DefaultHandler) runWatchdog() {
+	defer close(h.watchdogDone)
+
+	// Check a few times per timeout to bound the reaction time
+	interval := h.failsafeTimeout / 4
+	if interval <= 0 {
+		interval = h.failsafeTimeout
+	}
+	if h.currentSource != nil && (interval <= 0 || interval > CurrentLimitCheckInterval) {
+		interval = CurrentLimitCheckInterval
+	}
+	ticks, stopTicker := h.clock.NewTicker(interval)
+	defer stopTicker()
+
+	for {
+		select {
+		case <-h.watchdogStop:
+			return
+		case <-ticks:
+			// Wait for the running command instead of cancelling it, a command in flight is a sign of life
+			h.waitAndLock()
+			h.checkFailsafe()
+			h.checkCurrentLimit()
+			h.commandMutex.Unlock()
+		}
+	}
+}
+
+// runIdleKeepAlive re-writes the stop pulse width every keep-alive interval while the motor is stopped, so the ESCs
+// that disarm without a signal stay armed, until Close stops it. A coasting motor is left alone, like the failsafe
+// watchdog does.
+func (h *DefaultHandler) runIdleKeepAlive() {
+	defer close(h.keepAliveDone)
+
+	ticks, stopTicker := h.clock.NewTicker(h.idleKeepAlive)
+	defer stopTicker()
+
+	for {
+		select {
+		case <-h.keepAliveStop:
+			return
+		case <-ticks:
+			// Wait for the running command, the motor is not idle while a command is in flight
+			h.waitAndLock()
+			if !h.isClosed && !h.isManualOverride && h.direction == DirectionStop {
+				_ = h.rewritePulseWidth()
+			}
+			h.commandMutex.Unlock()
+		}
+	}
+}
+
+// checkFailsafe stops the motor if no command or feed arrived within the failsafe timeout
+func (h *DefaultHandler) checkFailsafe() {
+	if h.failsafeTimeout <= 0 || h.direction == DirectionStop || h.direction == DirectionCoast {
+		return
+	}
+
+	// Check if a command or feed arrived in time, the feed does not lock the handler
+	h.stateMutex.RLock()
+	isFed := h.isFailsafeActive || h.since(h.lastFeed) <= h.failsafeTimeout
+	h.stateMutex.RUnlock()
+	if isFed {
+		return
+	}
+
+	// Log the failsafe
+	if h.logger != nil {
+		h.logger.AddMessage(
+			failsafePrefix,
+			true,
+		)
+		h.logger.Warning()
+	}
+
+	// Disengage the cruise without waiting for it, since the watchdog holds the handler it waits for, then ramp to
+	// neutral and set the failsafe flag
+	h.stopCruise()
+	_ = h.reportError(h.setSpeed(0, DirectionStop), OpFailsafe)
+	h.stateMutex.Lock()
+	h.isFailsafeActive = true
+	h.stateMutex.Unlock()
+	if h.onFailsafe != nil {
+		h.onFailsafe()
+	}
+}
+
+// updateCurrentLimit reads the sensed current and updates the current limit protection, lowering the speed ceiling
+// while the current exceeds the limit and releasing it once the current drops below the hysteresis threshold
+//
+// Returns:
+//
+// True if the current limit protection is engaged, otherwise false
+func (h *DefaultHandler) updateCurrentLimit() bool {
+	// Check if the current limit protection is enabled
+	if h.currentSource == nil {
+		return false
+	}
+
+	current := h.currentSource()
+	if current > h.currentLimit {
+		// Pull the ceiling back from the current speed
+		ceiling := h.speedMagnitude()
+		if h.isCurrentLimited && h.currentCeiling < ceiling {
+			ceiling = h.currentCeiling
+		}
+		ceiling -= CurrentLimitPullbackStep
+		if ceiling < 0 {
+			ceiling = 0
+		}
+		isEngaged := !h.isCurrentLimited
+		h.stateMutex.Lock()
+		h.currentCeiling = ceiling
+		h.isCurrentLimited = true
+		h.stateMutex.Unlock()
+
+		// Check if the protection has just been engaged
+		if isEngaged {
+			// Log the current limit
+			if h.logger != nil {
+				h.logger.AddMessageWithFloat64(
+					currentLimitPrefix,
+					current,
+					h.floatPrecision,
+					true,
+					true,
+				)
+				h.logger.Warning()
+			}
+			if h.onCurrentLimit != nil {
+				h.onCurrentLimit()
+			}
+		}
+	} else if h.isCurrentLimited && current < h.currentLimit*CurrentLimitHysteresis {
+		// Release the protection
+		h.stateMutex.Lock()
+		h.isCurrentLimited = false
+		h.currentCeiling = 1
+		h.stateMutex.Unlock()
+	}
+	return h.isCurrentLimited
+}
+
+// checkCurrentLimit ramps the moving motor down to the speed ceiling of the current limit protection
+func (h *DefaultHandler) checkCurrentLimit() {
+	if h.direction != DirectionForward && h.direction != DirectionBackward {
+		return
+	}
+	if !h.updateCurrentLimit() || h.speedMagnitude() <= h.currentCeiling {
+		return
+	}
+
+	// Pull the motor back along the regular ramp without feeding the failsafe watchdog
+	h.stateMutex.Lock()
+	if h.speed < 0 {
+		h.speed = -h.currentCeiling
+	} else {
+		h.speed = h.currentCeiling
+	}
+	h.stateMutex.Unlock()
+	_ = h.reportError(h.graduallySetPulseWidth(h.speedToPulse(h.currentCeiling, h.direction)), OpCurrentLimit)
+	h.stateMutex.Lock()
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	h.emitEvent(EventTypeSpeedChanged, tinygoerrors.ErrorCodeNil)
+	if h.afterSetSpeedFunc != nil {
+		h.afterSetSpeedFunc(h.speed)
+	}
+}
+
+// GetThermalCeiling reads the temperature and returns the derated speed ceiling, logging whenever the ceiling changes.
+// It waits for the running command like the commands do, since it updates the derating.
+//
+// Returns:
+//
+// The speed ceiling between 0 and 1, 1 if the thermal derating is disabled or the temperature is below the start
+// temperature
+func (h *DefaultHandler) GetThermalCeiling() float64 {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	return h.updateThermalCeiling()
+}
+
+// updateThermalCeiling reads the temperature and updates the derated speed ceiling, logging whenever it changes
+//
+// Returns:
+//
+// The speed ceiling between 0 and 1
+func (h *DefaultHandler) updateThermalCeiling() float64 {
+	// Check if the thermal derating is enabled
+	if h.thermalSource == nil {
+		return 1
+	}
+
+	// Scale the ceiling linearly between the start and the max temperatures
+	temperature := h.thermalSource()
+	ceiling := 1.0
+	if temperature >= h.thermalMaxTemp {
+		ceiling = 0
+	} else if temperature > h.thermalStartTemp {
+		ceiling = (h.thermalMaxTemp - temperature) / (h.thermalMaxTemp - h.thermalStartTemp)
+	}
+
+	// Check if the ceiling has changed
+	h.stateMutex.Lock()
+	if ceiling == h.thermalCeiling {
+		h.stateMutex.Unlock()
+		return ceiling
+	}
+	wasDerating := h.thermalCeiling < 1
+	h.thermalCeiling = ceiling
+	h.stateMutex.Unlock()
+
+	// Log the derating events
+	if ceiling < 1 {
+		if h.logger != nil {
+			h.logger.AddMessageWithFloat64(
+				thermalDeratePrefix,
+				ceiling,
+				h.floatPrecision,
+				true,
+				true,
+			)
+			h.logger.Warning()
+		}
+	} else if wasDerating {
+		if h.logger != nil {
+			h.logger.AddMessage(
+				thermalRecoverPrefix,
+				true,
+			)
+			h.logger.Info()
+		}
+	}
+	return ceiling
+}
+
+// IsCurrentLimited returns whether the current limit protection is pulling the motor back.
+//
+// Returns:
+//
+// True if the sensed current exceeded the current limit and has not dropped below the hysteresis threshold yet,
+// otherwise false
+func (h *DefaultHandler) IsCurrentLimited() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isCurrentLimited
+}
+
+// Feed tells the failsafe watchdog that the control loop is alive, clearing the failsafe flag. Every speed command
+// also feeds the watchdog.
+func (h *DefaultHandler) Feed() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.lastFeed = h.clock.Now()
+	h.isFailsafeActive = false
+}
+
+// IsFailsafeActive returns whether the failsafe watchdog stopped the motor since the last command or feed.
+//
+// Returns:
+//
+// True if the failsafe was triggered, otherwise false
+func (h *DefaultHandler) IsFailsafeActive() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isFailsafeActive
+}
+
+// SetRPMSource sets the function reading the eRPM reported by the ESC telemetry. The readings are informational and do
+// not affect the control path.
+//
+// Parameters:
+//
+// rpmSource: Function returning the latest eRPM and whether the reading is valid, nil removes the source
+func (h *DefaultHandler) SetRPMSource(rpmSource func() (uint32, bool)) {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.rpmSource = rpmSource
+}
+
+// GetRPM reads the eRPM from the RPM source, caching it if the reading is valid.
+//
+// Returns:
+//
+// The latest valid eRPM and whether the current reading is valid
+func (h *DefaultHandler) GetRPM() (uint32, bool) {
+	// Read the RPM source, if set, without holding the lock
+	h.stateMutex.RLock()
+	rpmSource := h.rpmSource
+	h.stateMutex.RUnlock()
+	var rpm uint32
+	var ok bool
+	if rpmSource != nil {
+		rpm, ok = rpmSource()
+	}
+
+	// Cache the reading if it is valid
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	if ok {
+		h.rpm = rpm
+	}
+	return h.rpm, ok
+}
+
+// GetMechanicalRPM reads the eRPM from the RPM source and converts it into the mechanical RPM with the number of motor
+// poles.
+//
+// Returns:
+//
+// The latest valid mechanical RPM
+func (h *DefaultHandler) GetMechanicalRPM() uint32 {
+	rpm, _ := h.GetRPM()
+	return rpm / uint32(h.motorPoles/2)
+}
+
+// GetPulseHistory returns the pulse widths recorded by a dry run, in the order they were written.
+//
+// Returns:
+//
+// A copy of the recorded pulse widths, nil if the handler is not running a dry run
+func (h *DefaultHandler) GetPulseHistory() []uint32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return append([]uint32(nil), h.pulseHistory...)
+}
+
+// ClearPulseHistory clears the pulse widths recorded by a dry run.
+func (h *DefaultHandler) ClearPulseHistory() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.pulseHistory = h.pulseHistory[:0]
+}
+
+// AddChannel adds a channel driven in parallel with the same pulse width, e.g. for redundant ESCs fed from a single
+// command. The pin must be on the same PWM peripheral as the handler, so every channel shares its period.
+//
+// Parameters:
+//
+// pin: The pin connected to the additional ESC
+//
+// Returns:
+//
+// An error if the handler has been closed, it does not drive a PWM, the pin has no channel on its PWM or the current
+// pulse width could not be written to it, otherwise nil
+func (h *DefaultHandler) AddChannel(pin machine.Pin) tinygoerrors.ErrorCode {
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Get the channel of the pin on the PWM of the handler
+	if h.pwm == nil {
+		return ErrorCodeESCMotorFailedToGetPWMChannel
+	}
+	channel, err := h.pwm.Channel(pin)
+	if err != nil {
+		return ErrorCodeESCMotorFailedToGetPWMChannel
+	}
+
+	// Check if the channel is already driven
+	if channel == h.channel {
+		return tinygoerrors.ErrorCodeNil
+	}
+	for _, extraChannel := range h.extraChannels {
+		if channel == extraChannel {
+			return tinygoerrors.ErrorCodeNil
+		}
+	}
+	h.stateMutex.Lock()
+	h.extraChannels = append(h.extraChannels, channel)
+	h.stateMutex.Unlock()
+
+	// Drive the current pulse width on every channel
+	return h.rewritePulseWidth()
+}
+
+// Record starts capturing every pulse width written with the time elapsed since the call, discarding any previous
+// recording, so a sequence of maneuvers can be replayed later with Replay.
+func (h *DefaultHandler) Record() {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	h.isRecording = true
+	h.recordStart = h.clock.Now()
+	h.recordedSamples = nil
+}
+
+// StopRecording stops capturing the pulse widths written.
+//
+// Returns:
+//
+// The pulse samples captured since Record was called, nil if it was not
+func (h *DefaultHandler) StopRecording() []PulseSample {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+	samples := h.recordedSamples
+	h.isRecording = false
+	h.recordedSamples = nil
+	return samples
+}
+
+// IsRecording returns whether the pulse widths written are being captured.
+//
+// Returns:
+//
+// True if Record was called and StopRecording was not yet, otherwise false
+func (h *DefaultHandler) IsRecording() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.isRecording
+}
+
+// Replay drives the pulse widths of a recording with the recorded timing between the samples, bypassing the speed
+// mapping and the ramps like SetRawPulse does. The speed is reported as zero until the next speed command.
+//
+// Parameters:
+//
+// samples: The pulse samples to drive, as returned by StopRecording
+//
+// Returns:
+//
+// An error if the handler has been closed, a pulse width is out of range or it could not be written, otherwise nil
+func (h *DefaultHandler) Replay(samples []PulseSample) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Check if every pulse width is within the valid range before driving any of them
+	for _, sample := range samples {
+		if sample.Pulse < h.minPulseWidth || sample.Pulse > h.maxPulseWidth {
+			return h.reportError(ErrorCodeESCMotorRawPulseOutOfRange, OpReplay)
+		}
+	}
+	if len(samples) == 0 {
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Mark the speed bookkeeping as manual
+	h.setManualOverride()
+
+	// Drive every sample once its offset from the first one elapses
+	start := h.clock.Now()
+	first := samples[0].Elapsed
+	for _, sample := range samples {
+		if remaining := sample.Elapsed - first - h.since(start); remaining > 0 {
+			h.clock.Sleep(remaining)
+		}
+
+		// Feed the failsafe watchdog
+		h.Feed()
+		if errCode := h.writePulseWidth(sample.Pulse); errCode != tinygoerrors.ErrorCodeNil {
+			return h.reportError(errCode, OpReplay)
+		}
+	}
+
+	// Keep the direction on the side of the last pulse width for the next command
+	pulse := samples[len(samples)-1].Pulse
+	direction := h.pulseDirection(pulse)
+	h.completeDirection(direction)
+	h.stateMutex.Lock()
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
+}
+
+// EngageCruise latches the commanded speed and direction and keeps re-asserting them once per period in the background,
+// feeding the failsafe watchdog, until DisengageCruise is called. It suits the ESCs with their own failsafe when the
+// control loop cannot guarantee a steady command rate. Stop, EmergencyStop, Disarm, LockNeutral and the failsafe
+// watchdog disengage it. Engaging again latches the current speed instead.
+//
+// Returns:
+//
+// An error if the handler has been closed, otherwise nil
+func (h *DefaultHandler) EngageCruise() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Latch the commanded speed and direction
+	speed := h.speedMagnitude()
+	direction := h.commandedDirection()
+	if direction != DirectionForward && direction != DirectionBackward {
+		speed = 0
+		direction = DirectionStop
+	}
+
+	// Start re-asserting them in the background
+	h.stateMutex.Lock()
+	h.cruiseStop = make(chan struct{})
+	h.cruiseDone = make(chan struct{})
+	h.stateMutex.Unlock()
+	go h.runCruise(speed, direction, h.cruiseStop, h.cruiseDone)
+	return tinygoerrors.ErrorCodeNil
+}
+
+// runCruise re-asserts the latched speed once per period until stopped or the handler is closed
+//
+// Parameters:
+//
+// speed: The latched speed
+// direction: The latched direction
+// stop: The channel closed to stop the cruise
+// done: The channel closed once the cruise has stopped
+func (h *DefaultHandler) runCruise(speed float64, direction Direction, stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		h.stateMutex.RLock()
+		periodDelay := h.periodDelay
+		h.stateMutex.RUnlock()
+
+		select {
+		case <-stop:
+			return
+		case <-h.clock.After(periodDelay):
+			// Ramp back to the latched speed if another command moved it, otherwise re-write the current pulse width
+			h.waitAndLock()
+			if h.isClosed || h.isCruiseStopped(stop) {
+				h.commandMutex.Unlock()
+				return
+			}
+			if h.commandedDirection() != direction || h.speedMagnitude() != speed || h.isManualOverride {
+				_ = h.reportError(h.setSpeed(speed, direction), OpCruise)
+			} else {
+				h.Feed()
+				_ = h.reportError(h.writePulseWidth(h.pulse), OpCruise)
+			}
+			h.commandMutex.Unlock()
+		}
+	}
+}
+
+// DisengageCruise stops re-asserting the latched speed, returning the control to the caller. The motor keeps its
+// current speed. It does nothing if the cruise mode is not engaged.
+func (h *DefaultHandler) DisengageCruise() {
+	// Wait for the cruise to stop, the handler must be unlocked since the cruise waits for it on every period
+	if done := h.stopCruise(); done != nil {
+		<-done
+	}
+}
+
+// stopCruise signals the cruise to stop without waiting for it, so it can be called while holding the handler, e.g.
+// from the failsafe watchdog
+//
+// Returns:
+//
+// The channel closed once the cruise has stopped, or nil if the cruise mode is not engaged
+func (h *DefaultHandler) stopCruise() chan struct{} {
+	h.stateMutex.Lock()
+	stop, done := h.cruiseStop, h.cruiseDone
+	h.cruiseStop = nil
+	h.cruiseDone = nil
+	h.stateMutex.Unlock()
+
+	// Check if the cruise mode is engaged
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	return done
+}
+
+// isCruiseStopped returns whether the cruise was signaled to stop
+//
+// Parameters:
+//
+// stop: The channel closed to stop the cruise
+//
+// Returns:
+//
+// True if the stop channel is closed, otherwise false
+func (h *DefaultHandler) isCruiseStopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCruising returns whether the cruise mode is engaged.
+//
+// Returns:
+//
+// True if the latched speed is being re-asserted, otherwise false
+func (h *DefaultHandler) IsCruising() bool {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.cruiseStop != nil
+}
+
+// Close ramps the motor to neutral, stops the background goroutines of the handler and disables the PWM output if the
+// PWM supports it, which on most targets disables every channel sharing the same PWM peripheral. After Close, every
+// command returns ErrorCodeESCMotorClosed. Close is idempotent, calling it again does nothing.
+//
+// Returns:
+//
+// An error if the motor could not be ramped to neutral, otherwise nil
+func (h *DefaultHandler) Close() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.lockCommand()
+	if h.isClosed {
+		h.commandMutex.Unlock()
+		return tinygoerrors.ErrorCodeNil
+	}
+
+	// Ramp to neutral before releasing the output
+	errCode := h.reportError(h.setSpeed(0, DirectionStop), OpClose)
+	h.stateMutex.Lock()
+	h.isClosed = true
+	h.stateMutex.Unlock()
+
+	// Disable the PWM output if supported
+	if enabler, ok := h.pwm.(pwmEnabler); ok {
+		enabler.Enable(false)
+	}
+	h.commandMutex.Unlock()
+
+	// Stop the failsafe watchdog and the idle keep-alive once the handler is unlocked, since they wait for the handler
+	// on every check
+	if h.watchdogStop != nil {
+		close(h.watchdogStop)
+		<-h.watchdogDone
+	}
+	if h.keepAliveStop != nil {
+		close(h.keepAliveStop)
+		<-h.keepAliveDone
+	}
+	return errCode
+}
+
+// SetPulseWidths reconfigures the pulse widths at runtime using the same validation rules as the constructor, leaving
+// them unchanged if the validation fails. If the motor is running, the pulse width for the current speed is
+// recomputed and driven so the physical speed stays continuous.
+//
+// Parameters:
+//
+// minPulseWidth: Minimum pulse width for the ESC motor
+// neutralPulseWidth: Neutral pulse width for the ESC motor, ignored for unidirectional motors
+// maxPulseWidth: Maximum pulse width for the ESC motor
+//
+// Returns:
+//
+// An error if any pulse width is invalid, otherwise nil
+func (h *DefaultHandler) SetPulseWidths(minPulseWidth, neutralPulseWidth, maxPulseWidth uint32) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Check if the motor is unidirectional, in which case it stops at the min pulse width
+	if h.isUnidirectional {
+		neutralPulseWidth = minPulseWidth
+	}
+
+	// Check if the pulse widths are valid
+	if errCode := validatePulseWidths(
+		minPulseWidth,
+		neutralPulseWidth,
+		maxPulseWidth,
+		h.period,
+		h.isUnidirectional,
+	); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Check if the start offsets still leave a span in each direction
+	if h.forwardStartOffset >= maxPulseWidth-neutralPulseWidth ||
+		(h.backwardStartOffset != 0 && h.backwardStartOffset >= neutralPulseWidth-minPulseWidth) {
+		return ErrorCodeESCMotorInvalidStartOffset
+	}
+
+	// Update the pulse widths, keeping the neutral trim on top of the new neutral pulse width
+	h.stateMutex.Lock()
+	h.minPulseWidth = minPulseWidth
+	h.maxPulseWidth = maxPulseWidth
+	h.baseNeutralPulseWidth = neutralPulseWidth
+	h.setNeutralTrim(h.neutralTrim)
+	h.stateMutex.Unlock()
+
+	// Drive the pulse width for the current speed with the new pulse widths
+	return h.redrivePulseWidth()
+}
+
+// redrivePulseWidth ramps to the pulse width for the current speed after the pulse width mapping changed
+//
+// Returns:
+//
+// An error if a step could not be written, otherwise nil
+func (h *DefaultHandler) redrivePulseWidth() tinygoerrors.ErrorCode {
+	pulse := h.neutralPulseWidth
+	switch h.direction {
+	case DirectionStop:
+		pulse = h.stopPulseWidth()
+	case DirectionForward, DirectionBackward:
+		pulse = h.speedToPulse(h.speedMagnitude(), h.direction)
+	}
+	return h.graduallySetPulseWidth(pulse)
+}
+
+// setNeutralTrim applies a neutral trim to the base neutral pulse width, clamping the trimmed neutral pulse width so it
+// stays strictly between the min and max pulse widths and leaves a span after each start offset. The caller must hold
+// the state lock
+//
+// Parameters:
+//
+// trim: The signed offset to add to the base neutral pulse width
+func (h *DefaultHandler) setNeutralTrim(trim int32) {
+	// Check if the motor is unidirectional, in which case it stops at the min pulse width and is never trimmed
+	if h.isUnidirectional {
+		h.neutralPulseWidth = h.baseNeutralPulseWidth
+		h.neutralTrim = 0
+		return
+	}
+
+	// Clamp the trimmed neutral pulse width, in 64 bits so it never wraps around near the uint32 bounds
+	lowest := int64(h.minPulseWidth) + int64(h.backwardStartOffset) + 1
+	highest := int64(h.maxPulseWidth) - int64(h.forwardStartOffset) - 1
+	neutral := int64(h.baseNeutralPulseWidth) + int64(trim)
+	if neutral < lowest {
+		neutral = lowest
+	}
+	if neutral > highest {
+		neutral = highest
+	}
+	h.neutralPulseWidth = uint32(neutral)
+	h.neutralTrim = int32(neutral - int64(h.baseNeutralPulseWidth))
+}
+
+// SetNeutralTrim adds a signed offset to the configured neutral pulse width, e.g. to null out the creep of an ESC
+// whose true neutral drifts. The trimmed neutral pulse width is used in every calculation and clamped so it stays
+// strictly between the min and max pulse widths, then the pulse width for the current speed is driven again.
+//
+// Parameters:
+//
+// offset: The signed offset to add to the configured neutral pulse width, zero removes the trim
+//
+// Returns:
+//
+// An error if the handler has been closed, the motor is unidirectional or the pulse width could not be driven,
+// otherwise nil
+func (h *DefaultHandler) SetNeutralTrim(offset int32) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
 
-		// Check if the direction has changed
-		if (h.direction != direction) && (h.direction != DirectionStop) {
-			// Set to neutral pulse width first
-			h.graduallySetPulseWidth(h.neutralPulseWidth)
-		}
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
 
-		// Sleep the appropriate delay based on the direction change
-		if h.direction != DirectionForward && direction == DirectionForward {
-			if !h.lastStopTime.IsZero() {
-				time.Sleep(h.backwardToForwardDelay - time.Since(h.lastStopTime))
-			} else {
-				time.Sleep(h.backwardToForwardDelay)
-			}
-		} else if h.direction != DirectionBackward && direction == DirectionBackward {
-			if !h.lastStopTime.IsZero() {
-				time.Sleep(h.forwardToBackwardDelay - time.Since(h.lastStopTime))
-			} else {
-				time.Sleep(h.forwardToBackwardDelay)
-			}
+	// Check if the motor is unidirectional, in which case there is no neutral to trim
+	if h.isUnidirectional {
+		return ErrorCodeESCMotorNeutralTrimNotSupported
+	}
+
+	// Trim the neutral pulse width and drive the pulse width for the current speed with it
+	h.stateMutex.Lock()
+	h.setNeutralTrim(offset)
+	h.stateMutex.Unlock()
+	return h.redrivePulseWidth()
+}
+
+// GetNeutralTrim returns the offset added to the configured neutral pulse width.
+//
+// Returns:
+//
+// The signed offset, after the clamping, zero if the neutral pulse width is not trimmed
+func (h *DefaultHandler) GetNeutralTrim() int32 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.neutralTrim
+}
+
+// SetFrequency sets the frequency of the PWM signal at runtime, reconfiguring the PWM with the new period and
+// re-driving the current pulse width on it.
+//
+// Parameters:
+//
+// frequency: The frequency for the PWM signal
+//
+// Returns:
+//
+// An error if the frequency is zero, the pulse widths do not fit inside the new period or the PWM could not be
+// reconfigured, in which case the old frequency stays active, otherwise nil
+func (h *DefaultHandler) SetFrequency(frequency uint16) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Check if the frequency is zero
+	if frequency == 0 {
+		return ErrorCodeESCMotorZeroFrequency
+	}
+
+	// Check if the pulse widths still fit inside the new period
+	period := 1e9 / float64(frequency)
+	if errCode := validatePulseWidths(
+		h.minPulseWidth,
+		h.neutralPulseWidth,
+		h.maxPulseWidth,
+		uint32(period),
+		h.isUnidirectional,
+	); errCode != tinygoerrors.ErrorCodeNil {
+		return errCode
+	}
+
+	// Reconfigure the PWM unless running a dry run, restoring the old period if it fails
+	if !h.isDryRun {
+		if err := h.pwm.Configure(
+			machine.PWMConfig{
+				Period: uint64(period),
+			},
+		); err != nil {
+			_ = h.pwm.Configure(
+				machine.PWMConfig{
+					Period: uint64(h.period),
+				},
+			)
+			_ = h.rewritePulseWidth()
+			return ErrorCodeESCMotorFailedToConfigurePWM
 		}
+	}
 
-		// Continue with the gradual change until reaching the pulse width
-		h.graduallySetPulseWidth(pulse)
+	// Log the configured period
+	if h.logger != nil {
+		h.logger.AddMessageWithUint32(
+			setPeriodPrefix,
+			uint32(period),
+			true,
+			true,
+			false,
+		)
+		h.logger.Debug()
+	}
+
+	// Update the frequency and the period
+	h.stateMutex.Lock()
+	h.frequency = frequency
+	h.period = uint32(period)
+	h.periodDelay = time.Duration(period)
+	h.stateMutex.Unlock()
+
+	// Re-drive the current pulse width on the new period
+	return h.rewritePulseWidth()
+}
+
+// commandedDirection returns the direction as commanded by the caller, before the polarity inversion
+//
+// Returns:
+//
+// The commanded direction
+func (h *DefaultHandler) commandedDirection() Direction {
+	if h.isPolarityInverted {
+		return h.direction.InvertedDirection()
+	}
+	return h.direction
+}
+
+// speedMagnitude returns the current speed regardless of the direction
+//
+// Returns:
+//
+// The current speed as a value between 0 and 1
+func (h *DefaultHandler) speedMagnitude() float64 {
+	if h.speed < 0 {
+		return -h.speed
+	}
+	return h.speed
+}
+
+// SetMaxForwardSpeed sets the maximum forward speed, immediately ramping down to it if the motor is moving forward
+// faster.
+//
+// Parameters:
+//
+// maxForwardSpeed: The maximum forward percentage speed value for the motor
+//
+// Returns:
+//
+// An error if the max forward speed is invalid or the speed could not be ramped down, otherwise nil
+func (h *DefaultHandler) SetMaxForwardSpeed(maxForwardSpeed float64) tinygoerrors.ErrorCode {
+	if maxForwardSpeed <= 0 || maxForwardSpeed > 1 {
+		return ErrorCodeESCMotorInvalidMaxForwardSpeed
+	}
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.maxForwardSpeed = maxForwardSpeed
+	h.stateMutex.Unlock()
+
+	// Re-clamp the current speed
+	if h.commandedDirection() == DirectionForward && h.speedMagnitude() > maxForwardSpeed {
+		return h.reportError(h.setSpeed(maxForwardSpeed, DirectionForward), OpSetSpeed)
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// SetMaxBackwardSpeed sets the maximum backward speed, immediately ramping down to it if the motor is moving backward
+// faster.
+//
+// Parameters:
+//
+// maxBackwardSpeed: The maximum backward percentage speed value for the motor
+//
+// Returns:
+//
+// An error if the max backward speed is invalid or the speed could not be ramped down, otherwise nil
+func (h *DefaultHandler) SetMaxBackwardSpeed(maxBackwardSpeed float64) tinygoerrors.ErrorCode {
+	if maxBackwardSpeed <= 0 || maxBackwardSpeed > 1 {
+		return ErrorCodeESCMotorInvalidMaxBackwardSpeed
+	}
+	h.waitAndLock()
+	defer h.commandMutex.Unlock()
+	h.stateMutex.Lock()
+	h.maxBackwardSpeed = maxBackwardSpeed
+	h.stateMutex.Unlock()
+
+	// Re-clamp the current speed
+	if h.commandedDirection() == DirectionBackward && h.speedMagnitude() > maxBackwardSpeed {
+		return h.reportError(h.setSpeed(maxBackwardSpeed, DirectionBackward), OpSetSpeed)
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// GetMaxForwardSpeed returns the maximum forward speed.
+//
+// Returns:
+//
+// The maximum forward percentage speed value for the motor
+func (h *DefaultHandler) GetMaxForwardSpeed() float64 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.maxForwardSpeed
+}
+
+// GetMaxBackwardSpeed returns the maximum backward speed.
+//
+// Returns:
+//
+// The maximum backward percentage speed value for the motor
+func (h *DefaultHandler) GetMaxBackwardSpeed() float64 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.maxBackwardSpeed
+}
+
+// IsThrottleSideSwapped returns whether the halves of the pulse widths mapped to the forward and backward directions
+// are swapped.
+//
+// Returns:
+//
+// True if the forward direction maps below neutral and the backward direction above it, otherwise false
+func (h *DefaultHandler) IsThrottleSideSwapped() bool {
+	return h.isThrottleSideSwapped
+}
+
+// GetFilteredSpeed returns the speed filtered by the input smoothing, the speed the last command was mapped from.
+//
+// Returns:
+//
+// The filtered speed, negative when moving backward, zero if the input smoothing is disabled
+func (h *DefaultHandler) GetFilteredSpeed() float64 {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	if h.isPolarityInverted {
+		return -h.filteredSpeed
+	}
+	return h.filteredSpeed
+}
 
-		// Update the current direction
-		h.direction = direction
-		if direction != DirectionStop {
-			// Reset the last stop time if not stopping
-			h.lastStopTime = time.Time{}
+// GetMinEffectiveSpeed returns the min effective speed, the speed the nonzero commands below it are bumped up to.
+//
+// Returns:
+//
+// The min effective speed, zero if disabled
+func (h *DefaultHandler) GetMinEffectiveSpeed() float64 {
+	return h.minEffectiveSpeed
+}
+
+// GetSpeedRange returns the usable speed range of a commanded direction, e.g. to scale a throttle slider. The lower
+// bound is the deadband, since the lower speeds stop the motor.
+//
+// Parameters:
+//
+// direction: Direction of the motor, as commanded
+//
+// Returns:
+//
+// The minimum and maximum speeds that move the motor in the direction, both zero if the direction does not move it or
+// the motor cannot go backward
+func (h *DefaultHandler) GetSpeedRange(direction Direction) (float64, float64) {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	switch direction {
+	case DirectionForward:
+		return h.deadband, h.maxForwardSpeed
+	case DirectionBackward:
+		if h.isUnidirectional {
+			return 0, 0
 		}
+		return h.deadband, h.maxBackwardSpeed
+	default:
+		return 0, 0
+	}
+}
+
+// EmergencyStop slams the pulse width to the stop pulse width, neutral unless set otherwise by WithStopPulse, with a
+// single write, skipping the gradual ramp and every delay. It works even if movement is disabled and still calls the
+// after set speed function with a zero speed. It disengages the cruise mode first.
+//
+// Returns:
+//
+// An error if the handler has been closed or the neutral pulse width could not be written, otherwise nil
+func (h *DefaultHandler) EmergencyStop() tinygoerrors.ErrorCode {
+	h.DisengageCruise()
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
 
-		// Set the last update time
-		h.lastUpdate = time.Now()
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
 	}
 
-	// Log the speed change
+	// Write the stop pulse width at once
+	pulse := h.stopPulseWidth()
+	h.stateMutex.Lock()
+	h.rampTarget = pulse
+	h.stateMutex.Unlock()
+	if errCode := h.writePulseWidth(pulse); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpEmergencyStop)
+	}
+	isDirectionChanged := h.direction != DirectionStop
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.filteredSpeed = 0
+	h.isManualOverride = false
+	h.direction = DirectionStop
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+
+	// Log the emergency stop
 	if h.logger != nil {
-		switch direction {
-		case DirectionStop:
-			h.logger.AddMessage(
-				stopPrefix,
-				true,
-			)
-			h.logger.Debug()
-		case DirectionForward:
-			h.logger.AddMessageWithFloat64(
-				setSpeedForwardPrefix,
-				speed,
-				Float64Precision,
-				true,
-				true,
-			)
-			h.logger.Debug()
-		case DirectionBackward:
-			h.logger.AddMessageWithFloat64(
-				setSpeedBackwardPrefix,
-				speed,
-				Float64Precision,
-				true,
-				true,
-			)
-			h.logger.Debug()
-		}
+		h.logger.AddMessage(
+			emergencyStopPrefix,
+			true,
+		)
+		h.logger.Warning()
 	}
 
+	// Emit the state-change events
+	if isDirectionChanged {
+		h.emitEvent(EventTypeDirectionChanged, tinygoerrors.ErrorCodeNil)
+	}
+	h.emitEvent(EventTypeSpeedChanged, tinygoerrors.ErrorCodeNil)
+
 	// Call the after set speed function if provided
 	if h.afterSetSpeedFunc != nil {
 		h.afterSetSpeedFunc(h.speed)
@@ -388,65 +4806,231 @@ func (h *DefaultHandler) SetSpeed(
 	return tinygoerrors.ErrorCodeNil
 }
 
-// GetSpeed returns the current speed of the ESC motor.
+// SetRawPulse ramps to an exact pulse width, bypassing the speed mapping, e.g. for bench tests or the special commands
+// of some ESCs. The speed is reported as zero until the next speed command.
+//
+// Parameters:
+//
+// pulse: The pulse width value to drive, between the min and max pulse widths
 //
 // Returns:
 //
-// The current speed of the ESC motor as an int16 value.
-func (h *DefaultHandler) GetSpeed() float64 {
-	if h.direction == DirectionBackward {
-		return -h.speed
-	} else if h.direction == DirectionForward {
-		return h.speed
+// An error if the handler has been closed, the pulse width is out of range or it could not be written, otherwise nil
+func (h *DefaultHandler) SetRawPulse(pulse uint32) tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
 	}
-	return h.speed
+
+	// Check if the pulse width is within the valid range
+	if pulse < h.minPulseWidth || pulse > h.maxPulseWidth {
+		return h.reportError(ErrorCodeESCMotorRawPulseOutOfRange, OpSetRawPulse)
+	}
+
+	// Feed the failsafe watchdog
+	h.Feed()
+
+	// Mark the speed bookkeeping as manual, keeping the direction on the side of the pulse width for the next command
+	h.setManualOverride()
+	direction := h.pulseDirection(pulse)
+
+	// Ramp to the pulse width
+	errCode := h.graduallySetPulseWidth(pulse)
+	if errCode != ErrorCodeESCMotorMovementDisabled {
+		h.completeDirection(direction)
+	}
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpSetRawPulse)
+	}
+	h.stateMutex.Lock()
+	h.lastUpdate = h.clock.Now()
+	h.stateMutex.Unlock()
+	return tinygoerrors.ErrorCodeNil
 }
 
-// Stop sets the ESC motor speed to 0 (stop).
+// Reset returns the handler to the state left by the constructor, ramping to the stop pulse width, zeroing the speed,
+// clearing the update and stop times and driving the stop pulse width again. It does not run the PWM configuration
+// again.
 //
 // Returns:
 //
-// An error if the speed could not be set to 0, otherwise nil.
-func (h *DefaultHandler) Stop() tinygoerrors.ErrorCode {
-	return h.SetSpeed(0, DirectionStop)
+// An error if the handler has been closed or the neutral pulse width could not be driven, otherwise nil
+func (h *DefaultHandler) Reset() tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return ErrorCodeESCMotorClosed
+	}
+
+	// Ramp to the stop pulse width
+	if errCode := h.graduallySetPulseWidth(h.stopPulseWidth()); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpReset)
+	}
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.isManualOverride = false
+	h.direction = DirectionStop
+	h.lastUpdate = time.Time{}
+	h.stateMutex.Unlock()
+
+	// Drive the stop pulse width again, clearing the stop time it sets
+	if errCode := h.writePulseWidth(h.stopPulseWidth()); errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpReset)
+	}
+	h.stateMutex.Lock()
+	h.lastStopTime = time.Time{}
+	h.stateMutex.Unlock()
+
+	// Log the stop
+	if h.isLogEnabled(LogCategoryStop) {
+		h.logger.AddMessage(
+			stopPrefix,
+			true,
+		)
+		h.log(LogCategoryStop)
+	}
+
+	// Call the after set speed function if provided
+	if h.afterSetSpeedFunc != nil {
+		h.afterSetSpeedFunc(h.speed)
+	}
+	return tinygoerrors.ErrorCodeNil
 }
 
-// SetSpeedForward sets the ESC motor speed forward.
+// SetThrottle sets the signed throttle of an ESC running in 3D mode, ramping straight through neutral on reversal.
 //
 // Parameters:
 //
-// speed: Speed value between 0 (stop) and maxForwardSpeed (full forward).
+// throttle: Throttle value between -maxBackwardSpeed (full backward) and maxForwardSpeed (full forward), it is clamped
+// to the max speeds
 //
 // Returns:
 //
-// An error if the speed could not be set, otherwise nil.
-func (h *DefaultHandler) SetSpeedForward(speed float64) tinygoerrors.ErrorCode {
-	// Check if the speed is within the valid range
-	if speed < 0 {
-		speed = 0
-	}
-	if speed > h.maxForwardSpeed {
-		speed = h.maxForwardSpeed
+// An error if the 3D mode is disabled or the throttle could not be set, otherwise nil
+func (h *DefaultHandler) SetThrottle(throttle float64) tinygoerrors.ErrorCode {
+	if !h.is3DMode {
+		return h.reportError(ErrorCodeESCMotorMode3DDisabled, OpSetThrottle)
 	}
-	return h.SetSpeed(speed, DirectionForward)
+	return setSignedSpeed(h, clampSignedSpeed(throttle))
 }
 
-// SetSpeedBackward sets the ESC motor speed backward.
+// Brake engages the active braking of ESCs that brake when commanded backward while moving forward. The ESC only
+// reverses on a backward command received after a stop, honoring the forward to backward delay.
 //
 // Parameters:
 //
-// speed: Speed value between 0 (stop) and maxBackwardSpeed (full backward).
+// force: Braking force between 0 and 1, mapped onto the backward pulse widths
 //
 // Returns:
 //
-// An error if the speed could not be set, otherwise nil.
-func (h *DefaultHandler) SetSpeedBackward(speed float64) tinygoerrors.ErrorCode {
-	// Check if the speed is within the valid range
-	if speed < 0 {
-		speed = 0
+// An error if the brake mode is disabled, the force is out of range or it could not be set, otherwise nil
+func (h *DefaultHandler) Brake(force float64) tinygoerrors.ErrorCode {
+	if !h.isBrakeMode {
+		return h.reportError(ErrorCodeESCMotorBrakeModeDisabled, OpBrake)
 	}
-	if speed > h.maxBackwardSpeed {
-		speed = h.maxBackwardSpeed
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the motor is moving forward, otherwise braking would reverse it
+	if h.direction != DirectionForward && h.direction != DirectionBrake {
+		return h.reportError(h.setSpeed(0, DirectionStop), OpStop)
+	}
+
+	// Brake through the backward command, taking the polarity inversion into account
+	direction := DirectionBackward
+	if h.isPolarityInverted {
+		direction = direction.InvertedDirection()
 	}
-	return h.SetSpeed(speed, DirectionBackward)
+	return h.reportError(h.setSpeed(force, direction), OpBrake)
+}
+
+// Coast ramps the pulse width down to neutral and leaves the motor free-spinning, which is recorded as coasting
+// instead of an active stop, so the failsafe watchdog does not re-assert neutral. On most ESCs neutral already means no
+// drive, making it a bookkeeping and telemetry distinction, while brake-capable ESCs can hook a free-spin into it.
+//
+// Returns:
+//
+// An error if the handler has been closed or the neutral pulse width could not be driven, otherwise nil
+func (h *DefaultHandler) Coast() tinygoerrors.ErrorCode {
+	h.lockCommand()
+	defer h.commandMutex.Unlock()
+
+	// Check if the handler has been closed
+	if h.isClosed {
+		return h.reportError(ErrorCodeESCMotorClosed, OpCoast)
+	}
+
+	// Feed the failsafe watchdog
+	h.Feed()
+
+	// Ramp the pulse width down to neutral
+	h.stateMutex.Lock()
+	h.speed = 0
+	h.isManualOverride = false
+	h.stateMutex.Unlock()
+	errCode := h.graduallySetPulseWidth(h.neutralPulseWidth)
+	isDirectionChanged := h.completeDirection(DirectionCoast)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		return h.reportError(errCode, OpCoast)
+	}
+	if h.isRampCancelled() {
+		return tinygoerrors.ErrorCodeNil
+	}
+	h.completeRamp(isDirectionChanged)
+
+	// Log the coasting
+	if h.isLogEnabled(LogCategoryStop) {
+		h.logger.AddMessage(
+			coastPrefix,
+			true,
+		)
+		h.log(LogCategoryStop)
+	}
+
+	// Call the after set speed function if provided
+	if h.afterSetSpeedFunc != nil {
+		h.afterSetSpeedFunc(h.speed)
+	}
+	return tinygoerrors.ErrorCodeNil
+}
+
+// GetStatus returns a snapshot of the speed, direction, pulse width and state flags of the handler in a single read.
+//
+// Returns:
+//
+// The status of the handler
+func (h *DefaultHandler) GetStatus() Status {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	signedSpeed := h.signedSpeed()
+	return Status{
+		Speed:              math.Abs(signedSpeed),
+		SignedSpeed:        signedSpeed,
+		Direction:          h.commandedDirection(),
+		Pulse:              h.pulse,
+		NeutralPulseWidth:  h.neutralPulseWidth,
+		IsArmed:            h.isArmed,
+		IsFailsafeActive:   h.isFailsafeActive,
+		IsNeutralLocked:    h.isNeutralLocked,
+		IsCurrentLimited:   h.isCurrentLimited,
+		IsManualOverride:   h.isManualOverride,
+		IsClosed:           h.isClosed,
+		RemainingRampSteps: h.remainingRampSteps(),
+	}
+}
+
+// GetDirection returns the current direction of the ESC motor as commanded, before the polarity inversion.
+//
+// Returns:
+//
+// The current direction, DirectionBrake while braking the forward motion and DirectionCoast while coasting
+func (h *DefaultHandler) GetDirection() Direction {
+	h.stateMutex.RLock()
+	defer h.stateMutex.RUnlock()
+	return h.commandedDirection()
 }
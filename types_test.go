@@ -0,0 +1,564 @@
+package tinygo_escmotor
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+// newTestHandler creates a dry run handler on a fake clock, closed once the test ends
+//
+// Parameters:
+//
+// t: The test
+// opts: The options to configure the handler, applied after the fake clock so they can replace it
+//
+// Returns:
+//
+// The handler
+func newTestHandler(t *testing.T, opts ...Option) *DefaultHandler {
+	t.Helper()
+	h, errCode := NewSimHandler(append([]Option{withClock(newFakeClock())}, opts...)...)
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("NewSimHandler() = %v", errCode)
+	}
+	t.Cleanup(
+		func() {
+			_ = h.Close()
+		},
+	)
+	return h
+}
+
+// mustSucceed fails the test if a command returned an error
+//
+// Parameters:
+//
+// t: The test
+// errCode: The error returned by the command
+func mustSucceed(t *testing.T, errCode tinygoerrors.ErrorCode) {
+	t.Helper()
+	if errCode != tinygoerrors.ErrorCodeNil {
+		t.Fatalf("command failed: %v", errCode)
+	}
+}
+
+type (
+	// pulseRecorder is the duty writer recording every pulse width written, failing from a given write on
+	pulseRecorder struct {
+		mutex    sync.Mutex
+		writes   []uint32
+		failFrom int
+	}
+)
+
+// errRecorderFailure is the error returned by the pulseRecorder once it fails
+var errRecorderFailure = errors.New("duty writer failure")
+
+// write records a pulse width, or fails if the failing write has been reached
+func (r *pulseRecorder) write(pulse uint32) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.failFrom > 0 && len(r.writes)+1 >= r.failFrom {
+		return errRecorderFailure
+	}
+	r.writes = append(r.writes, pulse)
+	return nil
+}
+
+// option returns the option installing the recorder as the duty writer
+func (r *pulseRecorder) option() Option {
+	return WithDutyWriter(r.write)
+}
+
+// failAfter makes the recorder fail from the nth write after the ones already recorded
+func (r *pulseRecorder) failAfter(n int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failFrom = len(r.writes) + n
+}
+
+// since returns the pulse widths written after the first n writes
+func (r *pulseRecorder) since(n int) []uint32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]uint32(nil), r.writes[n:]...)
+}
+
+// count returns the number of pulse widths written
+func (r *pulseRecorder) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.writes)
+}
+
+func TestSetSpeedStopsRampOnDutyFailure(t *testing.T) {
+	recorder := &pulseRecorder{}
+	h := newTestHandler(t, recorder.option(), WithPulseStep(100000))
+	before := recorder.count()
+	recorder.failAfter(3)
+
+	if errCode := h.SetSpeedForward(1); errCode != ErrorCodeESCMotorFailedToSetDuty {
+		t.Fatalf("SetSpeedForward() = %v, want ErrorCodeESCMotorFailedToSetDuty", errCode)
+	}
+
+	// The ramp aborts at the failing write and the pulse width is the last one written
+	if got := recorder.since(before); len(got) != 2 || got[0] != 1600000 || got[1] != 1700000 {
+		t.Fatalf("wrote %v, want [1600000 1700000]", got)
+	}
+	if got := h.GetPulse(); got != 1700000 {
+		t.Fatalf("GetPulse() = %d, want 1700000", got)
+	}
+}
+
+func TestDirectionChangeDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		prepare func(t *testing.T, h *DefaultHandler, clock *fakeClock)
+		want    time.Duration
+	}{
+		{
+			name: "stopped longer than the delay",
+			prepare: func(t *testing.T, h *DefaultHandler, clock *fakeClock) {
+				mustSucceed(t, h.SetSpeedForward(0.5))
+				mustSucceed(t, h.Stop())
+				clock.Advance(500 * time.Millisecond)
+			},
+			want: 0,
+		},
+		{
+			name: "stopped shorter than the delay",
+			prepare: func(t *testing.T, h *DefaultHandler, clock *fakeClock) {
+				mustSucceed(t, h.SetSpeedForward(0.5))
+				mustSucceed(t, h.Stop())
+				clock.Advance(100 * time.Millisecond)
+			},
+			want: 200 * time.Millisecond,
+		},
+		{
+			// The period delay catch-up waits first, then the neutral pass-through starts the whole delay
+			name: "reversing without a stop",
+			prepare: func(t *testing.T, h *DefaultHandler, clock *fakeClock) {
+				mustSucceed(t, h.SetSpeedForward(0.5))
+			},
+			want: 20*time.Millisecond + 300*time.Millisecond,
+		},
+		{
+			// No stop time was ever recorded, so the whole delay remains
+			name:    "never stopped",
+			opts:    []Option{WithSkippedInitialStop(true)},
+			prepare: func(t *testing.T, h *DefaultHandler, clock *fakeClock) {},
+			want:    300 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				clock := newFakeClock()
+				h := newTestHandler(
+					t,
+					append([]Option{withClock(clock), WithDirectionDelays(0, 300*time.Millisecond)}, tt.opts...)...,
+				)
+				tt.prepare(t, h, clock)
+				start := clock.Slept()
+
+				mustSucceed(t, h.SetSpeedBackward(0.5))
+				if slept := clock.Slept() - start; slept != tt.want {
+					t.Fatalf("waited %v, want %v", slept, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestZeroPulseStepIsRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "pulse step", opt: WithPulseStep(0)},
+		{name: "forward pulse step", opt: WithForwardPulseStep(0)},
+		{name: "backward pulse step", opt: WithBackwardPulseStep(0)},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if _, errCode := NewSimHandler(tt.opt); errCode != ErrorCodeESCMotorInvalidPulseStep {
+					t.Fatalf("NewSimHandler() = %v, want ErrorCodeESCMotorInvalidPulseStep", errCode)
+				}
+			},
+		)
+	}
+
+	// The step set at runtime is rejected too, and the ramps keep their previous step
+	h := newTestHandler(t, WithPulseStep(100000))
+	zero := uint32(0)
+	if errCode := h.SetPulseStep(&zero); errCode != ErrorCodeESCMotorInvalidPulseStep {
+		t.Fatalf("SetPulseStep(0) = %v, want ErrorCodeESCMotorInvalidPulseStep", errCode)
+	}
+	if step, ok := h.GetPulseStep(); !ok || step != 100000 {
+		t.Fatalf("GetPulseStep() = %d, %v, want 100000, true", step, ok)
+	}
+	mustSucceed(t, h.SetSpeedForward(1))
+}
+
+func TestRampStepsAreEvenlySpaced(t *testing.T) {
+	tests := []struct {
+		name  string
+		step  uint32
+		delta int64
+		want  []int64
+	}{
+		{name: "delta 100, step 30", step: 30, delta: 100, want: []int64{25, 50, 75, 100}},
+		{name: "delta -100, step 30", step: 30, delta: -100, want: []int64{-25, -50, -75, -100}},
+		{name: "delta 90, step 30", step: 30, delta: 90, want: []int64{30, 60, 90}},
+		{name: "delta 101, step 30", step: 30, delta: 101, want: []int64{25, 50, 75, 101}},
+		{name: "delta below the step", step: 30, delta: 7, want: []int64{7}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := &pulseRecorder{}
+				h := newTestHandler(t, recorder.option(), WithPulseStep(tt.step))
+				before := recorder.count()
+				neutral := int64(h.GetNeutralPulseWidth())
+
+				mustSucceed(t, h.SetRawPulse(uint32(neutral+tt.delta)))
+
+				// Every step is within one of the even share of the distance and the last one lands on the target
+				got := recorder.since(before)
+				if len(got) != len(tt.want) {
+					t.Fatalf("wrote %v, want the offsets %v", got, tt.want)
+				}
+				for i := range got {
+					if offset := int64(got[i]) - neutral; offset != tt.want[i] {
+						t.Fatalf("step %d offset %d, want %d", i, offset, tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestSetSpeedRejectsUnknownDirection(t *testing.T) {
+	for _, direction := range []Direction{DirectionNil, Direction(99)} {
+		h := newTestHandler(t)
+		pulse := h.GetPulse()
+		if errCode := h.SetSpeed(0.5, direction); errCode != ErrorCodeESCMotorUnknownDirection {
+			t.Fatalf("SetSpeed(0.5, %d) = %v, want ErrorCodeESCMotorUnknownDirection", direction, errCode)
+		}
+		if got := h.GetPulse(); got != pulse || !h.IsStopped() {
+			t.Fatalf("SetSpeed(0.5, %d) drove the pulse width %d", direction, got)
+		}
+	}
+}
+
+func TestInvalidCommandsDoNotFeedTheFailsafe(t *testing.T) {
+	clock := newFakeClock()
+	h := newTestHandler(t, withClock(clock), WithFailsafe(100*time.Millisecond))
+	mustSucceed(t, h.SetSpeedForward(0.5))
+	clock.Advance(200 * time.Millisecond)
+
+	// None of the rejected commands counts as a sign of life
+	tests := []struct {
+		speed     float64
+		direction Direction
+		want      tinygoerrors.ErrorCode
+	}{
+		{speed: math.NaN(), direction: DirectionForward, want: ErrorCodeESCMotorSpeedOutOfRange},
+		{speed: 2, direction: DirectionForward, want: ErrorCodeESCMotorSpeedOutOfRange},
+		{speed: -0.5, direction: DirectionBackward, want: ErrorCodeESCMotorSpeedOutOfRange},
+		{speed: 0.5, direction: DirectionNil, want: ErrorCodeESCMotorUnknownDirection},
+	}
+	for _, tt := range tests {
+		if errCode := h.SetSpeed(tt.speed, tt.direction); errCode != tt.want {
+			t.Fatalf("SetSpeed(%v, %d) = %v, want %v", tt.speed, tt.direction, errCode, tt.want)
+		}
+	}
+
+	clock.TickAndWait(t, 25*time.Millisecond)
+	if !h.IsFailsafeActive() || !h.IsStopped() {
+		t.Fatal("failsafe not triggered after only invalid commands")
+	}
+}
+
+func TestConcurrentCommandsAndGetters(t *testing.T) {
+	h := newTestHandler(t, WithPulseStep(50000), WithDirectionDelays(10*time.Millisecond, 10*time.Millisecond))
+
+	// Commands and getters run concurrently, the race detector flags any unguarded access
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				speed := float64(j%10) / 10
+				switch (i + j) % 3 {
+				case 0:
+					_ = h.SetSpeedForward(speed)
+				case 1:
+					_ = h.SetSpeedBackward(speed)
+				default:
+					_ = h.Stop()
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				_ = h.GetStatus()
+				_ = h.GetSpeed()
+				_ = h.GetPulse()
+				_ = h.IsStopped()
+				_ = h.MarshalState()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The commands were serialized, so the last one leaves a consistent state
+	mustSucceed(t, h.Stop())
+	if !h.IsStopped() || h.GetSpeed() != 0 || h.GetPulse() != h.GetNeutralPulseWidth() {
+		t.Fatalf("inconsistent state after Stop: %+v", h.GetStatus())
+	}
+}
+
+func TestMovementDisabledMidRampStops(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		stop uint32
+	}{
+		{name: "neutral stop pulse", stop: DefaultNeutralPulseWidth},
+		{name: "min stop pulse", opts: []Option{WithStopPulse(StopPulseMin)}, stop: DefaultMinPulseWidth},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// The movement gets disabled once two steps of the ramp have been written
+				recorder := &pulseRecorder{}
+				before := -1
+				isMovementEnabled := func() bool {
+					return before < 0 || recorder.count()-before < 2
+				}
+				h := newTestHandler(
+					t,
+					append(
+						[]Option{recorder.option(), WithPulseStep(100000), WithMovementEnabled(isMovementEnabled)},
+						tt.opts...,
+					)...,
+				)
+				before = recorder.count()
+				start := h.GetPulse()
+
+				if errCode := h.SetSpeedForward(1); errCode != ErrorCodeESCMotorMovementDisabled {
+					t.Fatalf("SetSpeedForward() = %v, want ErrorCodeESCMotorMovementDisabled", errCode)
+				}
+
+				// The pulse width stops climbing after two steps and goes straight to the stop pulse width
+				got := recorder.since(before)
+				if len(got) != 3 || got[0] <= start || got[1] <= got[0] || got[2] != tt.stop {
+					t.Fatalf("wrote %v, want two climbing steps and then %d", got, tt.stop)
+				}
+				if !h.IsStopped() || h.GetSpeed() != 0 {
+					t.Fatalf("IsStopped() = %v with speed %v after the abort", h.IsStopped(), h.GetSpeed())
+				}
+			},
+		)
+	}
+}
+
+func TestBackwardPulseNeverDropsBelowMin(t *testing.T) {
+	for _, speed := range []float64{0.9999999, 0.99999999999, math.Nextafter(1, 0), 1} {
+		h := newTestHandler(t)
+		mustSucceed(t, h.SetSpeedBackward(speed))
+		if got := h.GetPulse(); got < h.GetMinPulseWidth() || got > h.GetNeutralPulseWidth() {
+			t.Fatalf("SetSpeedBackward(%v) drove %d, below the min or above neutral", speed, got)
+		}
+	}
+}
+
+func TestStopsDisengageCruise(t *testing.T) {
+	stops := []struct {
+		name string
+		stop func(h *DefaultHandler) tinygoerrors.ErrorCode
+	}{
+		{name: "EmergencyStop", stop: (*DefaultHandler).EmergencyStop},
+		{name: "Stop", stop: (*DefaultHandler).Stop},
+		{name: "Disarm", stop: (*DefaultHandler).Disarm},
+		{name: "LockNeutral", stop: (*DefaultHandler).LockNeutral},
+	}
+	for _, tt := range stops {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				h := newTestHandler(t)
+				mustSucceed(t, h.SetSpeedForward(0.5))
+				mustSucceed(t, h.EngageCruise())
+
+				mustSucceed(t, tt.stop(h))
+				if h.IsCruising() {
+					t.Fatalf("%s() left the cruise engaged", tt.name)
+				}
+
+				// Give a leftover cruise the time to re-drive the latched speed
+				time.Sleep(10 * time.Millisecond)
+				if h.GetSpeed() != 0 || !h.IsStopped() {
+					t.Fatalf("speed %v and pulse %d after %s(), want a stop", h.GetSpeed(), h.GetPulse(), tt.name)
+				}
+			},
+		)
+	}
+}
+
+func TestBoostThresholdAppliesToEveryPath(t *testing.T) {
+	paths := []struct {
+		name     string
+		setSpeed func(h *DefaultHandler) tinygoerrors.ErrorCode
+		want     tinygoerrors.ErrorCode
+	}{
+		{
+			name: "SetSpeed",
+			setSpeed: func(h *DefaultHandler) tinygoerrors.ErrorCode {
+				return h.SetSpeed(1, DirectionForward)
+			},
+			want: ErrorCodeESCMotorBoostNotEnabled,
+		},
+		{
+			name: "SetSpeedWithoutSoftStart",
+			setSpeed: func(h *DefaultHandler) tinygoerrors.ErrorCode {
+				return h.SetSpeedWithoutSoftStart(1, DirectionForward)
+			},
+			want: ErrorCodeESCMotorBoostNotEnabled,
+		},
+		{
+			name: "SetSpeedAsync",
+			setSpeed: func(h *DefaultHandler) tinygoerrors.ErrorCode {
+				// The async command reports the clamping to the error handler only
+				errCode := h.SetSpeedAsync(1, DirectionForward)
+				h.Wait()
+				return errCode
+			},
+			want: tinygoerrors.ErrorCodeNil,
+		},
+	}
+	for _, tt := range paths {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				h := newTestHandler(t, WithBoostThreshold(0.5))
+				if errCode := tt.setSpeed(h); errCode != tt.want {
+					t.Fatalf("%s() = %v, want %v", tt.name, errCode, tt.want)
+				}
+				if h.GetSpeed() != 0.5 {
+					t.Fatalf("speed %v after %s(), want the boost threshold 0.5", h.GetSpeed(), tt.name)
+				}
+
+				// Once enabled, the boost region is reachable
+				h.EnableBoost()
+				_ = tt.setSpeed(h)
+				if h.GetSpeed() != 1 {
+					t.Fatalf("speed %v after %s() with the boost enabled, want 1", h.GetSpeed(), tt.name)
+				}
+			},
+		)
+	}
+}
+
+func TestPlayBeepRefusesWhileMoving(t *testing.T) {
+	recorder := &pulseRecorder{}
+	h := newTestHandler(t, recorder.option())
+	pattern := []BeepStep{{Pulse: h.GetNeutralPulseWidth() + 50, Duration: time.Millisecond}}
+
+	mustSucceed(t, h.SetSpeedForward(0.5))
+	before := recorder.count()
+	if errCode := h.PlayBeep(pattern); errCode != ErrorCodeESCMotorNotStopped {
+		t.Fatalf("PlayBeep() while moving = %v, want ErrorCodeESCMotorNotStopped", errCode)
+	}
+	if writes := recorder.since(before); len(writes) != 0 {
+		t.Fatalf("PlayBeep() while moving wrote %v", writes)
+	}
+
+	// Once stopped, the pattern plays and the stop pulse width is restored
+	mustSucceed(t, h.Stop())
+	before = recorder.count()
+	mustSucceed(t, h.PlayBeep(pattern))
+	if writes := recorder.since(before); len(writes) != 2 || writes[1] != h.GetNeutralPulseWidth() {
+		t.Fatalf("PlayBeep() wrote %v, want the pattern and the neutral pulse width", writes)
+	}
+}
+
+func TestEstimateSetSpeedDurationMatchesSetSpeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		setup     func(h *DefaultHandler)
+		speed     float64
+		direction Direction
+	}{
+		{
+			name:      "launch",
+			opts:      []Option{WithPulseStep(50), WithDirectionDelays(time.Second, time.Second)},
+			speed:     0.5,
+			direction: DirectionForward,
+		},
+		{
+			name: "reversal",
+			opts: []Option{WithPulseStep(50), WithDirectionDelays(time.Second, time.Second)},
+			setup: func(h *DefaultHandler) {
+				mustSucceed(t, h.SetSpeedForward(0.5))
+			},
+			speed:     0.5,
+			direction: DirectionBackward,
+		},
+		{
+			name: "brake",
+			opts: []Option{WithBrakeMode(true), WithPulseStep(50), WithDirectionDelays(time.Second, time.Second)},
+			setup: func(h *DefaultHandler) {
+				mustSucceed(t, h.SetSpeedForward(0.5))
+			},
+			speed:     0.5,
+			direction: DirectionBackward,
+		},
+		{
+			name:      "soft start",
+			opts:      []Option{WithSoftStart(0.1)},
+			speed:     1,
+			direction: DirectionForward,
+		},
+		{
+			name:      "input smoothing",
+			opts:      []Option{WithInputSmoothing(0.5)},
+			speed:     1,
+			direction: DirectionForward,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				clock := newFakeClock()
+				h := newTestHandler(t, append([]Option{withClock(clock)}, tt.opts...)...)
+				if tt.setup != nil {
+					tt.setup(h)
+				}
+
+				estimate := h.EstimateSetSpeedDuration(tt.speed, tt.direction)
+				before := clock.Slept()
+				mustSucceed(t, h.SetSpeed(tt.speed, tt.direction))
+				if slept := clock.Slept() - before; estimate != slept {
+					t.Fatalf("EstimateSetSpeedDuration() = %v, SetSpeed() blocked for %v", estimate, slept)
+				}
+			},
+		)
+	}
+
+	// The invalid commands are estimated as zero
+	h := newTestHandler(t)
+	if estimate := h.EstimateSetSpeedDuration(math.NaN(), DirectionForward); estimate != 0 {
+		t.Fatalf("EstimateSetSpeedDuration(NaN) = %v, want 0", estimate)
+	}
+}
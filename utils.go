@@ -0,0 +1,158 @@
+package tinygo_escmotor
+
+import (
+	tinygoerrors "github.com/ralvarezdev/tinygo-errors"
+)
+
+// pulseDistance returns the absolute distance between two pulse widths.
+//
+// Parameters:
+//
+// a: The first pulse width
+// b: The second pulse width
+//
+// Returns:
+//
+// The absolute distance between both pulse widths
+func pulseDistance(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// divideRoundingUp divides two unsigned integers rounding the result up.
+//
+// Parameters:
+//
+// dividend: The dividend
+// divisor: The divisor, must not be zero
+//
+// Returns:
+//
+// The quotient rounded up
+func divideRoundingUp(dividend, divisor uint32) uint32 {
+	quotient := dividend / divisor
+	if dividend%divisor != 0 {
+		quotient++
+	}
+	return quotient
+}
+
+// percentToSpeed converts a percentage into a speed, clamping it to 100.
+//
+// Parameters:
+//
+// percent: The percentage
+//
+// Returns:
+//
+// The speed as a value between 0 and 1
+func percentToSpeed(percent uint8) float64 {
+	if percent > 100 {
+		percent = 100
+	}
+	return float64(percent) / 100
+}
+
+// interpolatePulseWidth returns the pulse width of a step of an evenly interpolated ramp
+//
+// Parameters:
+//
+// from: The pulse width at the start of the ramp
+// to: The target pulse width of the ramp
+// step: The step of the ramp, from 1 up to steps
+// steps: The total number of steps of the ramp, must not be zero
+//
+// Returns:
+//
+// The pulse width of the step
+func interpolatePulseWidth(from, to, step, steps uint32) uint32 {
+	offset := uint32(uint64(pulseDistance(from, to)) * uint64(step) / uint64(steps))
+	if to < from {
+		return from - offset
+	}
+	return from + offset
+}
+
+// spanOffset returns the offset of a speed along a pulse width span, never larger than the span
+//
+// Parameters:
+//
+// span: The pulse width span
+// speed: The speed as a value between 0 and 1
+//
+// Returns:
+//
+// The offset along the span
+func spanOffset(span uint32, speed float64) uint32 {
+	offset := float64(span) * speed
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= float64(span) {
+		return span
+	}
+	return uint32(offset)
+}
+
+// clampPulseWidth clamps a pulse width to [minPulseWidth, maxPulseWidth]
+//
+// Parameters:
+//
+// pulse: The pulse width
+// minPulseWidth: The min pulse width
+// maxPulseWidth: The max pulse width
+//
+// Returns:
+//
+// The clamped pulse width
+func clampPulseWidth(pulse, minPulseWidth, maxPulseWidth uint32) uint32 {
+	if pulse < minPulseWidth {
+		return minPulseWidth
+	}
+	if pulse > maxPulseWidth {
+		return maxPulseWidth
+	}
+	return pulse
+}
+
+// clampSignedSpeed clamps a signed speed to [-1, 1]
+//
+// Parameters:
+//
+// speed: The signed speed
+//
+// Returns:
+//
+// The clamped speed
+func clampSignedSpeed(speed float64) float64 {
+	if speed > 1 {
+		return 1
+	}
+	if speed < -1 {
+		return -1
+	}
+	return speed
+}
+
+// setSignedSpeed sets a signed speed on the handler, clamped to its max forward and backward speeds
+//
+// Parameters:
+//
+// handler: The handler of the motor
+// speed: The signed speed, negative for backward
+//
+// Returns:
+//
+// An error if the speed could not be set, otherwise nil
+func setSignedSpeed(handler Handler, speed float64) tinygoerrors.ErrorCode {
+	switch DirectionFromSpeed(speed) {
+	case DirectionForward:
+		return handler.SetSpeedForward(speed)
+	case DirectionBackward:
+		return handler.SetSpeedBackward(-speed)
+	default:
+		return handler.Stop()
+	}
+}
@@ -0,0 +1,67 @@
+package tinygo_escmotor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpolatePulseWidthNearBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to uint32
+		step     uint32
+	}{
+		{name: "up to the max", from: math.MaxUint32 - 100, to: math.MaxUint32, step: 30},
+		{name: "down from the max", from: math.MaxUint32, to: math.MaxUint32 - 100, step: 30},
+		{name: "down to zero", from: 100, to: 0, step: 30},
+		{name: "up from zero", from: 0, to: 100, step: 30},
+		{name: "whole range up", from: 0, to: math.MaxUint32, step: math.MaxUint32 / 3},
+		{name: "whole range down", from: math.MaxUint32, to: 0, step: math.MaxUint32 / 3},
+		{name: "step larger than the distance", from: math.MaxUint32 - 5, to: math.MaxUint32, step: math.MaxUint32},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Walk the ramp like graduallySetPulseWidth does, every pulse must move toward the target without
+				// wrapping around and by no more than the step
+				steps := divideRoundingUp(pulseDistance(tt.from, tt.to), tt.step)
+				previous := tt.from
+				for i := uint32(1); i <= steps; i++ {
+					pulse := interpolatePulseWidth(tt.from, tt.to, i, steps)
+					if pulseDistance(pulse, tt.to) > pulseDistance(previous, tt.to) {
+						t.Fatalf("step %d: pulse %d moved away from %d", i, pulse, tt.to)
+					}
+					if pulseDistance(previous, pulse) > tt.step {
+						t.Fatalf("step %d: pulse %d jumped more than %d from %d", i, pulse, tt.step, previous)
+					}
+					previous = pulse
+				}
+				if previous != tt.to {
+					t.Fatalf("ramp ended at %d, want %d", previous, tt.to)
+				}
+			},
+		)
+	}
+}
+
+func TestSpanOffsetStaysWithinSpan(t *testing.T) {
+	tests := []struct {
+		span  uint32
+		speed float64
+		want  uint32
+	}{
+		{span: 500000, speed: 0, want: 0},
+		{span: 500000, speed: 0.5, want: 250000},
+		{span: 500000, speed: 0.9999999, want: 499999},
+		{span: 500000, speed: 1, want: 500000},
+		{span: 500000, speed: math.Nextafter(1, 2), want: 500000},
+		{span: 500000, speed: -0.1, want: 0},
+		{span: math.MaxUint32, speed: 0.9999999999999999, want: math.MaxUint32 - 1},
+		{span: math.MaxUint32, speed: 1, want: math.MaxUint32},
+	}
+	for _, tt := range tests {
+		if got := spanOffset(tt.span, tt.speed); got != tt.want {
+			t.Errorf("spanOffset(%d, %v) = %d, want %d", tt.span, tt.speed, got, tt.want)
+		}
+	}
+}